@@ -1,21 +1,32 @@
-
 URL="http://localhost:5001/familytree"
 # curl "${URL}/person"
 
-# create people Sonny, Mike, Martin, Phoebe, Anastasia, Ellen, Ursula, Oprah, Eric, Ariel, Dunny, Bruce, Jacqueline, Melody 
-curl -X POST \
-  -H "Content-Type: application/json" \
-  -d @- \
-"${URL}/people" <<EOF
-[	{		"name": "Sonny"	},	{		"name": "Mike"	},	{		"name": "Martin"	},	{		"name": "Phoebe"	},	{		"name": "Anastasia"	},	{		"name": "Ellen"	},	{		"name": "Ursula"	},	{		"name": "Oprah"	},	{		"name": "Eric"	},	{		"name": "Ariel"	},	{		"name": "Dunny"	},	{		"name": "Bruce"	},	{		"name": "Jacqueline"	},	{		"name": "Melody"	}]
-EOF
-
+# create people Sonny, Mike, Martin, Phoebe, Anastasia, Ellen, Ursula, Oprah, Eric, Ariel, Dunny, Bruce, Jacqueline, Melody
+# along with their relationships, referring to each of them by a temporary key
 curl -X POST \
   -H "Content-Type: application/json" \
   -d @- \
-  "http://localhost:5001/familytree/relationships" <<EOF
-  [ {
-    "children": "e4c04e63-4982-4357-8c26-dc277c76779a",
-    "parent": "393114f0-704b-4214-936a-97a804a14e71"
-  }]
+"${URL}/batch" <<EOF
+{
+  "people": [
+    { "key": "sonny", "name": "Sonny" }, { "key": "mike", "name": "Mike" },
+    { "key": "martin", "name": "Martin" }, { "key": "phoebe", "name": "Phoebe" },
+    { "key": "anastasia", "name": "Anastasia" }, { "key": "ellen", "name": "Ellen" },
+    { "key": "ursula", "name": "Ursula" }, { "key": "oprah", "name": "Oprah" },
+    { "key": "eric", "name": "Eric" }, { "key": "ariel", "name": "Ariel" },
+    { "key": "dunny", "name": "Dunny" }, { "key": "bruce", "name": "Bruce" },
+    { "key": "jacqueline", "name": "Jacqueline" }, { "key": "melody", "name": "Melody" }
+  ],
+  "relationships": [
+    { "parent": "sonny", "children": "martin" }, { "parent": "mike", "children": "martin" },
+    { "parent": "sonny", "children": "phoebe" }, { "parent": "mike", "children": "phoebe" },
+    { "parent": "martin", "children": "ellen" }, { "parent": "anastasia", "children": "ellen" },
+    { "parent": "martin", "children": "oprah" }, { "parent": "anastasia", "children": "oprah" },
+    { "parent": "phoebe", "children": "eric" }, { "parent": "ursula", "children": "eric" },
+    { "parent": "phoebe", "children": "ariel" }, { "parent": "ursula", "children": "ariel" },
+    { "parent": "eric", "children": "dunny" }, { "parent": "eric", "children": "bruce" },
+    { "parent": "jacqueline", "children": "dunny" }, { "parent": "jacqueline", "children": "bruce" },
+    { "parent": "bruce", "children": "melody" }
+  ]
+}
 EOF
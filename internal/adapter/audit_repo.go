@@ -0,0 +1,280 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"gorm.io/gorm"
+)
+
+// qPersonHistory lists the changes recorded in the audit log to a person
+// and to the relationships and partnerships they were part of, oldest first.
+const qPersonHistory = `
+	SELECT id, at, COALESCE(actor, ''), COALESCE(request_id, ''),
+		entity, entity_id, action, before, after
+	FROM audit_log
+	WHERE CAST(@id AS uuid) = ANY(people)
+	ORDER BY id`
+
+// ListPersonHistory returns the changes recorded in the audit log to a
+// person and to the relationships and partnerships they were part of.
+func (pr *PostgresRepository) ListPersonHistory(ctx context.Context, id string) ([]*domain.AuditEntry, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListPersonHistory")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	rows, err := pr.db.WithContext(ctx).Raw(qPersonHistory, map[string]interface{}{"id": id}).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make([]*domain.AuditEntry, 0)
+
+	for rows.Next() {
+		var (
+			e             domain.AuditEntry
+			before, after sql.NullString
+		)
+
+		err := rows.Scan(&e.ID, &e.At, &e.Actor, &e.RequestID, &e.Entity, &e.EntityID, &e.Action, &before, &after)
+		if err != nil {
+			return nil, err
+		}
+
+		if before.Valid {
+			e.Before = json.RawMessage(before.String)
+		}
+
+		if after.Valid {
+			e.After = json.RawMessage(after.String)
+		}
+
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+// qAuditStart returns when the audit log started recording changes.
+const qAuditStart = `SELECT (SELECT at FROM audit_log ORDER BY id LIMIT 1)`
+
+// pastState joins each entity c.id of a table to its state at @asOf, from
+// the audit log: after its latest change up to then or, changed only later,
+// before its first change, NULL when it did not exist. Entities never changed
+// since the audit log started are taken as they are.
+const pastState = `
+	LEFT JOIN LATERAL (
+		SELECT true AS found, CASE WHEN l.at <= @asOf THEN l.after ELSE l.before END AS data
+		FROM audit_log l
+		WHERE l.entity = '%[2]s' AND l.entity_id = c.id
+		ORDER BY l.at <= @asOf DESC, CASE WHEN l.at <= @asOf THEN -l.id ELSE l.id END
+		LIMIT 1
+	) s ON true
+	LEFT JOIN %[1]s t ON t.id = c.id`
+
+// qPastEdges lists the relationships or partnerships out of the trash at
+// @asOf between any of the people @ids and anyone else, with both ends.
+const qPastEdges = `
+	WITH candidates AS (
+		SELECT entity_id AS id FROM audit_log
+		WHERE entity = '%[2]s' AND people && CAST(@ids AS uuid[])
+		UNION
+		SELECT id FROM %[1]s
+		WHERE %[3]s = ANY(CAST(@ids AS uuid[])) OR %[4]s = ANY(CAST(@ids AS uuid[]))
+	), past AS (
+		SELECT CASE WHEN s.found THEN s.data ELSE to_jsonb(t) END AS data
+		FROM candidates c` + pastState + `
+	)
+	SELECT data->>'id', data->>'%[3]s', data->>'%[4]s'
+	FROM past
+	WHERE data IS NOT NULL
+	AND data->>'deleted_at' IS NULL
+	AND (CAST(data->>'%[3]s' AS uuid) = ANY(CAST(@ids AS uuid[])) OR CAST(data->>'%[4]s' AS uuid) = ANY(CAST(@ids AS uuid[])))`
+
+// qCreateSnapshot, qFillSnapshot and qShadowTable rebuild the entities @ids
+// of a table as they were at @asOf into a temporary table shadowing it until
+// the transaction ends.
+const (
+	qCreateSnapshot = `CREATE TEMP TABLE %[1]s_as_of ON COMMIT DROP AS SELECT * FROM %[1]s WITH NO DATA`
+	qFillSnapshot   = `
+		INSERT INTO %[1]s_as_of
+		SELECT r.* FROM (
+			SELECT CASE WHEN s.found THEN s.data ELSE to_jsonb(t) END AS data
+			FROM unnest(CAST(@ids AS uuid[])) AS c(id)` + pastState + `
+		) past, jsonb_populate_record(NULL::%[1]s, past.data) r
+		WHERE past.data IS NOT NULL`
+	qShadowTable = `ALTER TABLE %[1]s_as_of RENAME TO %[1]s`
+)
+
+// errSnapshotDone rolls back the transaction holding a snapshot once read.
+var errSnapshotDone = errors.New("snapshot done")
+
+// AsOf runs fn with a repository reading the family of a person as it was at
+// the given moment, rebuilt from the audit log: everyone connected to them
+// through relationships of any type, the partnerships of any of them and
+// those partners. Finding them takes a query per generation spanned, and
+// reading them one per person, relationship and partnership found, the rest
+// of the people left out. Moments before the audit log started are refused.
+// Nothing fn changes is kept.
+func (pr *PostgresRepository) AsOf(ctx context.Context, at time.Time, id string, fn func(app.Repository) error) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "AsOf")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	err := pr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var start sql.NullTime
+
+		if err := tx.Raw(qAuditStart).Row().Scan(&start); err != nil {
+			return err
+		}
+
+		if !start.Valid || at.Before(start.Time) {
+			return app.ErrInvalidAsOf
+		}
+
+		family, err := pastFamily(tx, at, id)
+		if err != nil {
+			return err
+		}
+
+		for _, t := range []struct {
+			table, entity string
+			ids           []string
+		}{
+			{"people", "person", family.people},
+			{"relationships", "relationship", family.relationships},
+			{"partnerships", "partnership", family.partnerships},
+		} {
+			if err := tx.Exec(fmt.Sprintf(qCreateSnapshot, t.table)).Error; err != nil {
+				return err
+			}
+
+			args := map[string]interface{}{"asOf": at, "ids": uuidArray(t.ids)}
+
+			if err := tx.Exec(fmt.Sprintf(qFillSnapshot, t.table, t.entity), args).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Exec(fmt.Sprintf(qShadowTable, t.table)).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := fn(&PostgresRepository{tx, pr.log}); err != nil {
+			return err
+		}
+
+		return errSnapshotDone
+	})
+	if errors.Is(err, errSnapshotDone) {
+		return nil
+	}
+
+	return err
+}
+
+// familyIDs holds the IDs of the people, relationships and partnerships
+// of a family.
+type familyIDs struct {
+	people, relationships, partnerships []string
+}
+
+// pastFamily lists the IDs of the family of a person at the given moment, as
+// AsOf reads it, going through their relationships generation by generation.
+func pastFamily(tx *gorm.DB, at time.Time, id string) (*familyIDs, error) {
+	id = strings.ToLower(id)
+	family := &familyIDs{people: []string{id}}
+	seen := map[string]bool{id: true}
+	seenEdges := make(map[string]bool)
+	frontier := []string{id}
+
+	for len(frontier) > 0 {
+		edges, err := pastEdges(tx, at, "relationships", "relationship", "parent_id", "child_id", frontier)
+		if err != nil {
+			return nil, err
+		}
+
+		frontier = nil
+
+		for _, e := range edges {
+			if !seenEdges[e[0]] {
+				seenEdges[e[0]] = true
+				family.relationships = append(family.relationships, e[0])
+			}
+
+			for _, p := range e[1:] {
+				if !seen[p] {
+					seen[p] = true
+					family.people = append(family.people, p)
+					frontier = append(frontier, p)
+				}
+			}
+		}
+	}
+
+	edges, err := pastEdges(tx, at, "partnerships", "partnership", "person1_id", "person2_id", family.people)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range edges {
+		family.partnerships = append(family.partnerships, e[0])
+
+		for _, p := range e[1:] {
+			if !seen[p] {
+				seen[p] = true
+				family.people = append(family.people, p)
+			}
+		}
+	}
+
+	return family, nil
+}
+
+// pastEdges returns the ID and both ends of the relationships or partnerships
+// of any of the given people at the given moment.
+func pastEdges(tx *gorm.DB, at time.Time, table, entity, end1, end2 string, ids []string) ([][3]string, error) {
+	args := map[string]interface{}{"asOf": at, "ids": uuidArray(ids)}
+
+	rows, err := tx.Raw(fmt.Sprintf(qPastEdges, table, entity, end1, end2), args).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	edges := make([][3]string, 0)
+
+	for rows.Next() {
+		var e [3]string
+		if err := rows.Scan(&e[0], &e[1], &e[2]); err != nil {
+			return nil, err
+		}
+
+		edges = append(edges, e)
+	}
+
+	return edges, rows.Err()
+}
+
+// uuidArray writes IDs as a PostgreSQL array literal.
+func uuidArray(ids []string) string {
+	return "{" + strings.Join(ids, ",") + "}"
+}
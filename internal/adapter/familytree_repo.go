@@ -2,81 +2,179 @@ package adapter
 
 import (
 	"context"
+	"sort"
 
-	"github.com/bhborges/family-tree-api/internal/app"
 	"github.com/bhborges/family-tree-api/internal/domain"
 )
 
-const qBuildFamilyTreeByPerson = `
-	WITH RECURSIVE family_tree AS (
-		SELECT id, parent_id, child_id
+// qAncestorsByPerson lists every parent edge above a person, of any type,
+// along with how many generations above them the parent is, leaving out
+// the relationships and people in the trash.
+// @depth limits the recursion to a number of generations, which also ends
+// it on cyclic data, and UNION keeps each edge once per depth it is found at.
+const qAncestorsByPerson = `
+	WITH RECURSIVE ancestors AS (
+		SELECT parent_id, child_id, type, 1 AS depth
 		FROM relationships
-		WHERE child_id = ?
-		UNION ALL
-		SELECT r.id, r.parent_id, r.child_id
+		WHERE child_id = @id
+		AND deleted_at IS NULL
+		UNION
+		SELECT r.parent_id, r.child_id, r.type, a.depth + 1
 		FROM relationships r
-		JOIN family_tree ft ON r.child_id = ft.parent_id
+		JOIN ancestors a ON r.child_id = a.parent_id
+		WHERE r.deleted_at IS NULL
+		AND a.depth < @depth
 	)
-	SELECT DISTINCT p.name as name, COALESCE(p2.name, '') as parent
-	FROM family_tree ft
-	JOIN people p ON ft.parent_id = p.id
-	LEFT JOIN relationships r ON r.child_id = p.id
-	LEFT JOIN people p2 ON r.parent_id = p2.id
-	UNION ALL
-	SELECT DISTINCT p.name as name, COALESCE(p2.name, '') as parent
-	FROM people p
-	LEFT JOIN relationships r ON r.child_id = p.id
-	LEFT JOIN people p2 ON r.parent_id = p2.id
-	WHERE p.id = ?`
+	SELECT a.parent_id, p.name AS parent, a.child_id, c.name AS child, a.type, MIN(a.depth) AS depth
+	FROM ancestors a
+	JOIN people p ON a.parent_id = p.id AND p.deleted_at IS NULL
+	JOIN people c ON a.child_id = c.id AND c.deleted_at IS NULL
+	GROUP BY a.parent_id, p.name, a.child_id, c.name, a.type`
+
+// qDescendantsByPerson lists every parent edge below a person,
+// along with how many generations below them the child is.
+// It limits depth and leaves out the trash like qAncestorsByPerson.
+const qDescendantsByPerson = `
+	WITH RECURSIVE descendants AS (
+		SELECT parent_id, child_id, type, 1 AS depth
+		FROM relationships
+		WHERE parent_id = @id
+		AND deleted_at IS NULL
+		UNION
+		SELECT r.parent_id, r.child_id, r.type, d.depth + 1
+		FROM relationships r
+		JOIN descendants d ON r.parent_id = d.child_id
+		WHERE r.deleted_at IS NULL
+		AND d.depth < @depth
+	)
+	SELECT d.parent_id, p.name AS parent, d.child_id, c.name AS child, d.type, MIN(d.depth) AS depth
+	FROM descendants d
+	JOIN people p ON d.parent_id = p.id AND p.deleted_at IS NULL
+	JOIN people c ON d.child_id = c.id AND c.deleted_at IS NULL
+	GROUP BY d.parent_id, p.name, d.child_id, c.name, d.type`
 
 // BuildFamilyTree builds the family tree of a given person ID, with the person as the root node.
-func (pr *PostgresRepository) BuildFamilyTree(ctx context.Context, id string) (*domain.FamilyTree, error) {
-	q := pr.db.Raw(qBuildFamilyTreeByPerson, id, id)
-
-	rows, err := q.Rows()
-
+// Ancestors are placed in negative generations and descendants in positive ones, and
+// members are ordered by generation, then name.
+func (pr *PostgresRepository) BuildFamilyTree(
+	ctx context.Context, id string, opts domain.TreeOptions,
+) (*domain.FamilyTree, error) {
+	root, err := pr.GetPersonByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
-	if rows.Err() != nil {
-		return nil, err
+	ms := make(map[string]*domain.Member)
+	member(ms, treeNode{root.ID, root.Name}, 0)
+
+	if opts.Mode != domain.TreeModeDescendants {
+		err := pr.walkFamilyTree(ctx, qAncestorsByPerson, id, opts.Generations,
+			func(parent, child treeNode, t domain.RelationshipType, depth int) {
+				member(ms, parent, -depth)
+				m := member(ms, child, -depth+1)
+				m.Relationships = append(m.Relationships, domain.FamilyRelationship{
+					ID: parent.id, Name: parent.name, Relationship: "parent", Type: t,
+				})
+			})
+		if err != nil {
+			return nil, err
+		}
 	}
-	defer rows.Close()
 
-	rs := make(map[string][]string)
+	if opts.Mode != domain.TreeModeAncestors {
+		err := pr.walkFamilyTree(ctx, qDescendantsByPerson, id, opts.Generations,
+			func(parent, child treeNode, t domain.RelationshipType, depth int) {
+				member(ms, child, depth)
+				m := member(ms, parent, depth-1)
+				m.Relationships = append(m.Relationships, domain.FamilyRelationship{
+					ID: child.id, Name: child.name, Relationship: "child", Type: t,
+				})
+			})
+		if err != nil {
+			return nil, err
+		}
+	}
 
-	for rows.Next() {
-		var name string
+	t := &domain.FamilyTree{Members: make([]*domain.Member, 0, len(ms))}
+	for _, m := range ms {
+		m.SortRelationships()
+		t.Members = append(t.Members, m)
+	}
 
-		var parent string
+	sort.Slice(t.Members, func(i, j int) bool {
+		a, b := t.Members[i], t.Members[j]
+		if a.Generation != b.Generation {
+			return a.Generation < b.Generation
+		}
 
-		err := rows.Scan(&name, &parent)
-		if err != nil {
-			return nil, err
+		if a.Name != b.Name {
+			return a.Name < b.Name
 		}
 
-		if parent != "" {
-			if _, ok := rs[name]; !ok {
-				rs[name] = []string{parent}
-			} else {
-				rs[name] = append(rs[name], parent)
-			}
+		return a.ID < b.ID
+	})
+
+	return t, nil
+}
+
+// treeNode identifies a person returned by the family tree queries.
+type treeNode struct {
+	id   string
+	name string
+}
+
+// walkFamilyTree runs one of the family tree queries and
+// calls fn for every parent edge it returns.
+func (pr *PostgresRepository) walkFamilyTree(
+	ctx context.Context, query, id string, depth int,
+	fn func(parent, child treeNode, t domain.RelationshipType, depth int),
+) error {
+	args := map[string]interface{}{"id": id, "depth": depth}
+
+	rows, err := pr.db.WithContext(ctx).Raw(query, args).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			parent, child treeNode
+			t             domain.RelationshipType
+			depth         int
+		)
+
+		if err := rows.Scan(&parent.id, &parent.name, &child.id, &child.name, &t, &depth); err != nil {
+			return err
 		}
+
+		fn(parent, child, t, depth)
 	}
 
-  if len(rs) == 0 { return nil, app.ErrPersonNotFound }
-	// create members slice and populate it with Member structs
-	ms := make([]*domain.Member, 0)
+	return rows.Err()
+}
 
-	for name, parentList := range rs {
-		m := &domain.Member{Name: name, Relationships: make([]domain.FamilyRelationship, len(parentList))}
-		for i, parent := range parentList {
-			m.Relationships[i] = domain.FamilyRelationship{Name: parent, Relationship: "parent"}
-		}
+// member returns the member for the given person, adding it if needed.
+// A member reached at several depths keeps the generation closest to the root.
+func member(ms map[string]*domain.Member, n treeNode, generation int) *domain.Member {
+	m, ok := ms[n.id]
+	if !ok {
+		m = &domain.Member{ID: n.id, Name: n.name, Generation: generation, Relationships: []domain.FamilyRelationship{}}
+		ms[n.id] = m
+	}
+
+	if abs(generation) < abs(m.Generation) {
+		m.Generation = generation
+	}
+
+	return m
+}
 
-		ms = append(ms, m)
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
 	}
 
-	return &domain.FamilyTree{Members: ms}, nil
+	return n
 }
@@ -0,0 +1,221 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"gorm.io/gorm"
+)
+
+// qDuplicateCandidates pairs people with alike names, unless their sex tells
+// them apart or they are related as parent and child or as partners, and
+// scores each pair by the similarity of their names plus a quarter for each
+// parent, child or partner they share, up to four, leaving out the trash.
+// @person limits the pairs to those including a person unless empty.
+const qDuplicateCandidates = `
+	WITH relatives AS (
+		SELECT parent_id AS id, child_id AS relative FROM relationships WHERE deleted_at IS NULL
+		UNION SELECT child_id, parent_id FROM relationships WHERE deleted_at IS NULL
+		UNION SELECT person1_id, person2_id FROM partnerships WHERE deleted_at IS NULL
+		UNION SELECT person2_id, person1_id FROM partnerships WHERE deleted_at IS NULL
+	),
+	candidates AS (
+		SELECT a.id AS id1, b.id AS id2, similarity(a.name, b.name) AS name_score
+		FROM people a
+		JOIN people b ON a.id < b.id AND a.name % b.name
+		WHERE a.deleted_at IS NULL AND b.deleted_at IS NULL
+		AND (@person = '' OR CAST(a.id AS text) = @person OR CAST(b.id AS text) = @person)
+		AND NOT (a.sex <> 'unknown' AND b.sex <> 'unknown' AND a.sex <> b.sex)
+		AND NOT EXISTS (
+			SELECT 1 FROM relatives r WHERE r.id = a.id AND r.relative = b.id
+		)
+	),
+	scored AS (
+		SELECT c.id1, c.id2, c.name_score, (
+			SELECT COUNT(*)
+			FROM relatives r1
+			JOIN relatives r2 ON r1.relative = r2.relative
+			WHERE r1.id = c.id1 AND r2.id = c.id2
+		) AS shared
+		FROM candidates c
+	)
+	SELECT id1, id2, name_score, shared, name_score + 0.25 * LEAST(shared, 4) AS score
+	FROM scored
+	WHERE name_score + 0.25 * LEAST(shared, 4) >= @min
+	ORDER BY score DESC, id1, id2
+	LIMIT @limit`
+
+// qDropRepeatedRelationships deletes the relationships of a duplicate
+// that the survivor already has with the same parent or child.
+const qDropRepeatedRelationships = `
+	DELETE FROM relationships r
+	WHERE r.deleted_at IS NULL
+	AND ((r.parent_id = @duplicate AND EXISTS (
+		SELECT 1 FROM relationships s
+		WHERE s.parent_id = @survivor AND s.child_id = r.child_id AND s.deleted_at IS NULL
+	))
+	OR (r.child_id = @duplicate AND EXISTS (
+		SELECT 1 FROM relationships s
+		WHERE s.child_id = @survivor AND s.parent_id = r.parent_id AND s.deleted_at IS NULL
+	)))`
+
+// qMoveRelationships re-points the relationships of a duplicate to the survivor.
+const qMoveRelationships = `
+	UPDATE relationships
+	SET parent_id = CASE WHEN parent_id = @duplicate THEN @survivor ELSE parent_id END,
+		child_id = CASE WHEN child_id = @duplicate THEN @survivor ELSE child_id END
+	WHERE (parent_id = @duplicate OR child_id = @duplicate)
+	AND deleted_at IS NULL`
+
+// qDropRepeatedPartnerships deletes the partnerships of a duplicate with the
+// survivor, and those with someone the survivor already has one with.
+const qDropRepeatedPartnerships = `
+	DELETE FROM partnerships p
+	WHERE (p.person1_id = @duplicate OR p.person2_id = @duplicate)
+	AND p.deleted_at IS NULL
+	AND (p.person1_id = @survivor OR p.person2_id = @survivor OR EXISTS (
+		SELECT 1 FROM partnerships s
+		WHERE (s.person1_id = @survivor OR s.person2_id = @survivor)
+		AND s.deleted_at IS NULL
+		AND CASE WHEN s.person1_id = @survivor THEN s.person2_id ELSE s.person1_id END
+			= CASE WHEN p.person1_id = @duplicate THEN p.person2_id ELSE p.person1_id END
+	))`
+
+// qMovePartnerships re-points the partnerships of a duplicate to the survivor.
+const qMovePartnerships = `
+	UPDATE partnerships
+	SET person1_id = CASE WHEN person1_id = @duplicate THEN @survivor ELSE person1_id END,
+		person2_id = CASE WHEN person2_id = @duplicate THEN @survivor ELSE person2_id END
+	WHERE (person1_id = @duplicate OR person2_id = @duplicate)
+	AND deleted_at IS NULL`
+
+// ListDuplicateCandidates returns the pairs of people that may be
+// the same person matching the query, the likeliest first.
+func (pr *PostgresRepository) ListDuplicateCandidates(ctx context.Context, q domain.DuplicateQuery) (
+	[]*domain.DuplicateCandidate, error,
+) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListDuplicateCandidates")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var rows []struct {
+		ID1       string
+		ID2       string
+		NameScore float64
+		Shared    int
+		Score     float64
+	}
+
+	args := map[string]interface{}{"person": q.PersonID, "min": q.MinScore, "limit": q.Limit}
+
+	err := pr.db.WithContext(ctx).Raw(qDuplicateCandidates, args).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, 2*len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.ID1, r.ID2)
+	}
+
+	people, err := pr.ListPeopleByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*domain.Person, len(people))
+	for _, p := range people {
+		byID[p.ID] = p
+	}
+
+	cs := make([]*domain.DuplicateCandidate, 0, len(rows))
+
+	for _, r := range rows {
+		cs = append(cs, &domain.DuplicateCandidate{
+			Person1:         byID[r.ID1],
+			Person2:         byID[r.ID2],
+			Score:           r.Score,
+			NameScore:       r.NameScore,
+			SharedRelatives: r.Shared,
+		})
+	}
+
+	return cs, nil
+}
+
+// MergePerson moves the relationships and partnerships of a duplicate over
+// to the survivor, dropping those the survivor already has, deletes the
+// duplicate for good, along with what of theirs was in the trash, and
+// records the merge, counting what was moved and dropped.
+// It is meant to run within a transaction.
+func (pr *PostgresRepository) MergePerson(ctx context.Context, m *domain.PersonMerge) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "MergePerson")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	args := map[string]interface{}{"survivor": m.SurvivorID, "duplicate": m.DuplicateID}
+
+	return pr.audited(ctx, func(db *gorm.DB) error {
+		for _, step := range []struct {
+			query string
+			count *int
+		}{
+			{qDropRepeatedRelationships, &m.RelationshipsDropped},
+			{qMoveRelationships, &m.RelationshipsMoved},
+			{qDropRepeatedPartnerships, &m.PartnershipsDropped},
+			{qMovePartnerships, &m.PartnershipsMoved},
+		} {
+			tx := db.Exec(step.query, args)
+			if tx.Error != nil {
+				return tx.Error
+			}
+
+			*step.count = int(tx.RowsAffected)
+		}
+
+		if err := purgePeople(db, []string{m.DuplicateID}); err != nil {
+			return err
+		}
+
+		return db.Create(m).Error
+	})
+}
+
+// ListPersonMerges returns the merges a person took part in, either as the
+// survivor or as the duplicate, or every merge when no person is given,
+// the latest first.
+func (pr *PostgresRepository) ListPersonMerges(ctx context.Context, personID string) ([]*domain.PersonMerge, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListPersonMerges")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var ms []*domain.PersonMerge
+
+	tx := pr.db.WithContext(ctx)
+
+	if personID != "" {
+		tx = tx.Where("survivor_id = ? OR duplicate_id = ?", personID, personID)
+	}
+
+	err := tx.Order("merged_at DESC").Find(&ms).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
@@ -0,0 +1,189 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"gorm.io/gorm"
+)
+
+// ListPartnerships returns a list with all partnerships registered.
+func (pr *PostgresRepository) ListPartnerships(ctx context.Context) ([]*domain.Partnership, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListPartnerships")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var ps []*domain.Partnership
+
+	tx := pr.db.WithContext(ctx)
+
+	err := tx.Find(&ps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// ListPartnershipsByPersonIDs returns every partnership
+// any of the given people is part of.
+func (pr *PostgresRepository) ListPartnershipsByPersonIDs(ctx context.Context, ids []string) (
+	[]*domain.Partnership, error,
+) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListPartnershipsByPersonIDs")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var ps []*domain.Partnership
+
+	if len(ids) == 0 {
+		return ps, nil
+	}
+
+	tx := pr.db.WithContext(ctx)
+
+	err := tx.Where("person1_id IN ? OR person2_id IN ?", ids, ids).Order("id").Find(&ps).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ps, nil
+}
+
+// GetPartnershipByID returns a partnership registered.
+// Filtered by ID.
+func (pr *PostgresRepository) GetPartnershipByID(ctx context.Context, id string) (*domain.Partnership, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "GetPartnership")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var p domain.Partnership
+
+	tx := pr.db.WithContext(ctx)
+
+	err := tx.Where(&domain.Partnership{ID: id}).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, app.ErrPartnershipNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// CreatePartnership create a new partnership.
+func (pr *PostgresRepository) CreatePartnership(ctx context.Context, dp domain.Partnership) (string, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "CreatePartnership")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	p := domain.Partnership{
+		Person1ID: dp.Person1ID,
+		Person2ID: dp.Person2ID,
+		Type:      dp.Type,
+		StartDate: dp.StartDate,
+		EndDate:   dp.EndDate,
+		EndReason: dp.EndReason,
+	}
+
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		return db.Create(&p).Error
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return p.ID, nil
+}
+
+// UpdatePartnership replaces every field of a partnership.
+func (pr *PostgresRepository) UpdatePartnership(ctx context.Context, dp *domain.Partnership) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "UpdatePartnership")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var rows int64
+
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		tx := db.Model(&domain.Partnership{}).
+			Where("id = ?", dp.ID).
+			Updates(map[string]interface{}{
+				"person1_id": dp.Person1ID,
+				"person2_id": dp.Person2ID,
+				"type":       dp.Type,
+				"start_date": dp.StartDate,
+				"end_date":   dp.EndDate,
+				"end_reason": dp.EndReason,
+			})
+		rows = tx.RowsAffected
+
+		return tx.Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return app.ErrPartnershipNotFound
+	}
+
+	return nil
+}
+
+// DeletePartnership delete a partnership.
+// Partnerships only go to the trash along with a partner,
+// so one deleted on its own is deleted for good.
+func (pr *PostgresRepository) DeletePartnership(ctx context.Context, id string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "DeletePartnership")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var rows int64
+
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		tx := db.Unscoped().Delete(&domain.Partnership{}, "id = ? AND deleted_at IS NULL", id)
+		rows = tx.RowsAffected
+
+		return tx.Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return app.ErrPartnershipNotFound
+	}
+
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/bhborges/family-tree-api/internal/app"
 	"github.com/bhborges/family-tree-api/internal/domain"
@@ -12,8 +14,10 @@ import (
 	"gorm.io/gorm"
 )
 
-// ListPeople returns a list with all people registered.
-func (pr *PostgresRepository) ListPeople(ctx context.Context) (
+// ListPeople returns the people registered matching the query, in its sort
+// order and starting after its cursor. Pages are read with a keyset on the
+// sort column and ID, so that deep pages cost no more than the first one.
+func (pr *PostgresRepository) ListPeople(ctx context.Context, q domain.PeopleQuery) (
 	[]*domain.Person, error,
 ) {
 	trans := newrelic.FromContext(ctx)
@@ -28,6 +32,34 @@ func (pr *PostgresRepository) ListPeople(ctx context.Context) (
 
 	tx := pr.db.WithContext(ctx)
 
+	if q.Name != "" {
+		tx = tx.Where("name ILIKE ?", "%"+escapeLike(q.Name)+"%")
+	}
+
+	if q.Surname != "" {
+		tx = tx.Where("LOWER(surname) = LOWER(?)", q.Surname)
+	}
+
+	column := "name"
+	if q.Sort.Field() == string(domain.SortBySurname) {
+		column = "surname"
+	}
+
+	direction, cmp := "ASC", ">"
+	if q.Sort.Desc() {
+		direction, cmp = "DESC", "<"
+	}
+
+	if q.After != nil {
+		tx = tx.Where(fmt.Sprintf("(%s, id) %s (?, ?)", column, cmp), q.After.Value, q.After.ID)
+	}
+
+	tx = tx.Order(fmt.Sprintf("%s %s, id %s", column, direction, direction))
+
+	if q.Limit > 0 {
+		tx = tx.Limit(q.Limit)
+	}
+
 	err := tx.Find(&p).Error
 	if err != nil {
 		return nil, err
@@ -36,6 +68,35 @@ func (pr *PostgresRepository) ListPeople(ctx context.Context) (
 	return p, err
 }
 
+// ListPeopleByIDs returns the people matching the given IDs.
+// Unknown IDs are ignored.
+func (pr *PostgresRepository) ListPeopleByIDs(ctx context.Context, ids []string) (
+	[]*domain.Person, error,
+) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListPeopleByIDs")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var p []*domain.Person
+
+	if len(ids) == 0 {
+		return p, nil
+	}
+
+	tx := pr.db.WithContext(ctx)
+
+	err := tx.Where("id IN ?", ids).Find(&p).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
 // GetPersonByID returns a person registered.
 // Filtered by ID.
 func (pr *PostgresRepository) GetPersonByID(ctx context.Context, id string) (*domain.Person, error) {
@@ -75,12 +136,23 @@ func (pr *PostgresRepository) CreatePerson(ctx context.Context, dp domain.Person
 	}
 
 	p := domain.Person{
-		Name: dp.Name,
+		Name:       dp.Name,
+		GivenName:  dp.GivenName,
+		Surname:    dp.Surname,
+		MaidenName: dp.MaidenName,
+		Sex:        dp.Sex,
+		BirthDate:  dp.BirthDate,
+		BirthPlace: dp.BirthPlace,
+		DeathDate:  dp.DeathDate,
+		DeathPlace: dp.DeathPlace,
+		Phonetic:   dp.Phonetic,
 	}
 
-	tx := pr.db.Create(&p)
-	if tx.Error != nil {
-		return "", tx.Error
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		return db.Create(&p).Error
+	})
+	if err != nil {
+		return "", err
 	}
 
 	return p.ID, nil
@@ -111,6 +183,8 @@ func (pr *PostgresRepository) CreatePeople(ctx context.Context, people []domain.
 }
 
 // UpdatePerson update a person.
+// Only the fields set in dp are changed, after those it clears are set
+// back to their default.
 func (pr *PostgresRepository) UpdatePerson(ctx context.Context, dp domain.Person) error {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
@@ -120,25 +194,108 @@ func (pr *PostgresRepository) UpdatePerson(ctx context.Context, dp domain.Person
 		defer segment.End()
 	}
 
-	tx := pr.db.Model(&dp).Update("name", dp.Name)
+	fields := map[string]interface{}{}
+
+	for _, field := range dp.Clear {
+		switch column := map[string]string{
+			"name": "name", "givenName": "given_name", "surname": "surname", "maidenName": "maiden_name",
+			"sex": "sex", "birthDate": "birth_date", "birthPlace": "birth_place",
+			"deathDate": "death_date", "deathPlace": "death_place",
+		}[field]; column {
+		case "":
+		case "sex":
+			fields[column] = domain.SexUnknown
+		case "birth_date", "death_date":
+			fields[column] = nil
+		default:
+			fields[column] = ""
+		}
+	}
+
+	for column, value := range map[string]string{
+		"name":        dp.Name,
+		"given_name":  dp.GivenName,
+		"surname":     dp.Surname,
+		"maiden_name": dp.MaidenName,
+		"sex":         string(dp.Sex),
+		"birth_place": dp.BirthPlace,
+		"death_place": dp.DeathPlace,
+		"phonetic":    dp.Phonetic,
+	} {
+		if value != "" {
+			fields[column] = value
+		}
+	}
+
+	if dp.BirthDate != nil {
+		fields["birth_date"] = dp.BirthDate
+	}
 
-	return tx.Error
+	if dp.DeathDate != nil {
+		fields["death_date"] = dp.DeathDate
+	}
+
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var rows int64
+
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		tx := db.Model(&domain.Person{ID: dp.ID}).Updates(fields)
+		rows = tx.RowsAffected
+
+		return tx.Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return app.ErrPersonNotFound
+	}
+
+	return nil
 }
 
-// DeletePerson delete a person.
-func (pr *PostgresRepository) DeletePerson(ctx context.Context, id string) error {
+// DeletePeople moves people to the trash along with every relationship and
+// partnership any of them is part of, all deleted at the same time so that
+// they can be restored together. It is meant to run within a transaction.
+func (pr *PostgresRepository) DeletePeople(ctx context.Context, ids []string) error {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
-		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "DeletePerson")
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "DeletePeople")
 		segment := trans.StartSegment(segmentName)
 
 		defer segment.End()
 	}
 
-	tx := pr.db.Delete(&domain.Person{ID: id})
-	if tx.Error != nil {
-		return tx.Error
+	if len(ids) == 0 {
+		return nil
 	}
 
-	return nil
+	now := time.Now()
+
+	return pr.audited(ctx, func(tx *gorm.DB) error {
+		err := tx.Model(&domain.Relationship{}).
+			Where("parent_id IN ? OR child_id IN ?", ids, ids).
+			Update("deleted_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Model(&domain.Partnership{}).
+			Where("person1_id IN ? OR person2_id IN ?", ids, ids).
+			Update("deleted_at", now).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Model(&domain.Person{}).Where("id IN ?", ids).Update("deleted_at", now).Error
+	})
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern so that s is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
@@ -2,18 +2,45 @@ package adapter
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 
-	"go.uber.org/zap"
-
 	"github.com/bhborges/family-tree-api/internal/app"
 	"github.com/bhborges/family-tree-api/internal/domain"
 
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"gorm.io/gorm"
 )
 
-// ListRelationship returns a list with all relationship registered.
-func (pr *PostgresRepository) ListRelationships(ctx context.Context) (
+// qConsanguinityDegree walks the biological ancestry of two people at once,
+// up to @max generations above each and leaving out the relationships in the
+// trash, and returns the smallest number of generations separating them
+// through a common ancestor, counting one of them as their own ancestor.
+// It is NULL when no such ancestor is found within @max generations in total.
+const qConsanguinityDegree = `
+	WITH RECURSIVE ancestry AS (
+		SELECT v.id AS root, v.id, 0 AS depth, ARRAY[v.id] AS path
+		FROM (VALUES (CAST(@a AS uuid)), (CAST(@b AS uuid))) AS v(id)
+		UNION ALL
+		SELECT a.root, r.parent_id, a.depth + 1, a.path || r.parent_id
+		FROM relationships r
+		JOIN ancestry a ON r.child_id = a.id
+		WHERE r.type = 'biological'
+		AND r.deleted_at IS NULL
+		AND NOT r.parent_id = ANY(a.path)
+		AND a.depth < @max
+	)
+	SELECT MIN(a.depth + b.depth)
+	FROM ancestry a
+	JOIN ancestry b ON a.id = b.id
+	WHERE a.root = CAST(@a AS uuid)
+	AND b.root = CAST(@b AS uuid)
+	AND a.depth + b.depth <= @max`
+
+// ListRelationship returns a list with all relationship registered
+// matching the given filter.
+func (pr *PostgresRepository) ListRelationships(ctx context.Context, f domain.RelationshipFilter) (
 	[]*domain.Relationship, error,
 ) {
 	trans := newrelic.FromContext(ctx)
@@ -28,6 +55,18 @@ func (pr *PostgresRepository) ListRelationships(ctx context.Context) (
 
 	tx := pr.db.WithContext(ctx)
 
+	if f.Type != "" {
+		tx = tx.Where("type = ?", f.Type)
+	}
+
+	if f.ParentID != "" {
+		tx = tx.Where("parent_id = ?", f.ParentID)
+	}
+
+	if f.ChildID != "" {
+		tx = tx.Where("child_id = ?", f.ChildID)
+	}
+
 	err := tx.Find(&r).Error
 	if err != nil {
 		return nil, err
@@ -36,167 +75,183 @@ func (pr *PostgresRepository) ListRelationships(ctx context.Context) (
 	return r, err
 }
 
-// CreateRelationship create a new relationship.
-func (pr *PostgresRepository) CreateRelationship(ctx context.Context, dr domain.Relationship) (string, error) {
+// ListRelationshipsByPersonIDs returns every relationship in which
+// any of the given people is either the parent or the child.
+func (pr *PostgresRepository) ListRelationshipsByPersonIDs(ctx context.Context, ids []string) (
+	[]*domain.Relationship, error,
+) {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
-		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "CreateRelationShip")
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListRelationshipsByPersonIDs")
 		segment := trans.StartSegment(segmentName)
 
 		defer segment.End()
 	}
 
-	rs, err := pr.listRelationshipsByParentAndChildIDs(dr.ParentID, dr.ChildID)
+	var r []*domain.Relationship
+
+	if len(ids) == 0 {
+		return r, nil
+	}
+
+	tx := pr.db.WithContext(ctx)
+
+	err := tx.Where("parent_id IN ? OR child_id IN ?", ids, ids).Find(&r).Error
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetRelationshipByID returns a relationship registered.
+// Filtered by ID.
+func (pr *PostgresRepository) GetRelationshipByID(ctx context.Context, id string) (*domain.Relationship, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "GetRelationship")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var r domain.Relationship
+
+	tx := pr.db.WithContext(ctx)
+
+	err := tx.Where(&domain.Relationship{ID: id}).First(&r).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, app.ErrRelationshipNotFound
+	}
+
+	if err != nil {
+		return nil, err
 	}
 
-	if pr.checkIncestuousOffspring(rs) {
-		return "", app.ErrIncestuousOffspring
+	return &r, nil
+}
+
+// CreateRelationship create a new relationship.
+func (pr *PostgresRepository) CreateRelationship(ctx context.Context, dr domain.Relationship) (string, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "CreateRelationShip")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
 	}
 
 	r := domain.Relationship{
 		ParentID: dr.ParentID,
 		ChildID:  dr.ChildID,
+		Type:     dr.Type,
 	}
 
-	tx := pr.db.Create(&r)
-	if tx.Error != nil {
-		return "", tx.Error
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		return db.Create(&r).Error
+	})
+	if err != nil {
+		return "", err
 	}
 
 	return r.ID, nil
 }
 
-func (pr *PostgresRepository) UpdateRelationship(ctx context.Context, dr *domain.Relationship) error {
+// ConsanguinityDegree returns the degree of kinship between two people,
+// the generations from each of them up to their closest common biological
+// ancestor, and whether they are related within the given degree at all.
+func (pr *PostgresRepository) ConsanguinityDegree(ctx context.Context, id1, id2 string, maxDegree int) (int, bool, error) {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
-		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "UpdateRelationShip")
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ConsanguinityDegree")
 		segment := trans.StartSegment(segmentName)
 
 		defer segment.End()
 	}
 
-	tx := pr.db.Model(&domain.Relationship{}).
-		Where("id = ?", dr.ID).
-		Updates(map[string]interface{}{
-			"parent_id": dr.ParentID,
-			"child_id":  dr.ChildID,
-		})
+	var degree sql.NullInt64
 
-	if tx.Error != nil {
-		return tx.Error
-	}
+	args := map[string]interface{}{"a": id1, "b": id2, "max": maxDegree}
 
-	if tx.RowsAffected == 0 {
-		return app.ErrNoRowsUpdated
+	err := pr.db.WithContext(ctx).Raw(qConsanguinityDegree, args).Row().Scan(&degree)
+	if err != nil {
+		return 0, false, err
 	}
 
-	return nil
+	return int(degree.Int64), degree.Valid, nil
 }
 
-func (pr *PostgresRepository) DeleteRelationship(ctx context.Context, id string) error {
+func (pr *PostgresRepository) UpdateRelationship(ctx context.Context, dr *domain.Relationship) error {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
-		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "DeleteRelationShip")
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "UpdateRelationShip")
 		segment := trans.StartSegment(segmentName)
 
 		defer segment.End()
 	}
 
-	tx := pr.db.Delete(&domain.Relationship{}, "id = ?", id)
+	fields := map[string]interface{}{}
 
-	if tx.Error != nil {
-		return tx.Error
+	for column, value := range map[string]string{
+		"parent_id": dr.ParentID,
+		"child_id":  dr.ChildID,
+		"type":      string(dr.Type),
+	} {
+		if value != "" {
+			fields[column] = value
+		}
 	}
 
-	if tx.RowsAffected == 0 {
-		return app.ErrNoRowsInserted
+	if len(fields) == 0 {
+		return nil
 	}
 
-	return nil
-}
+	var rows int64
+
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		tx := db.Model(&domain.Relationship{}).
+			Where("id = ?", dr.ID).
+			Updates(fields)
+		rows = tx.RowsAffected
 
-// listParentsByID lists all parents that have the given parentID.
-func (pr *PostgresRepository) listParentsByID(parentID string) ([]*domain.Relationship, error) {
-	relationships := []*domain.Relationship{}
-	tx := pr.db.Debug().Where("child_id = ?", parentID).Find(&relationships)
+		return tx.Error
+	})
+	if err != nil {
+		return err
+	}
 
-	if tx.Error != nil {
-		return nil, tx.Error
+	if rows == 0 {
+		return app.ErrNoRowsUpdated
 	}
 
-	return relationships, nil
+	return nil
 }
 
-// listRelationshipsByParentAndChildIDs lists all relationships that match the given parentID and childID.
-func (pr *PostgresRepository) listRelationshipsByParentAndChildIDs(parentID, childID string) ([]*domain.Relationship, error) {
-	rs := []*domain.Relationship{}
-
-	// get all relationships where the given parent or child is involved
-	tx := pr.db.Debug().Where(
-		"(parent_id = ? OR child_id = ?) OR (parent_id = ? OR child_id = ?)", parentID, childID, childID, parentID,
-	).Find(&rs)
-	if tx.Error != nil {
-		return nil, tx.Error
-	}
-
-	// check for additional relationships involving the parents
-	rsParents := []*domain.Relationship{}
-	tx = pr.db.Debug().Where(
-		"parent_id IN (?) OR child_id IN (?)", parentID, parentID,
-	).Find(&rsParents)
+// DeleteRelationship moves a relationship to the trash.
+func (pr *PostgresRepository) DeleteRelationship(ctx context.Context, id string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "DeleteRelationShip")
+		segment := trans.StartSegment(segmentName)
 
-	if tx.Error != nil {
-		return nil, tx.Error
+		defer segment.End()
 	}
 
-	rdChilds := []*domain.Relationship{}
+	var rows int64
 
-	for _, r := range rsParents {
-		if r.ParentID != parentID {
-			// get all relationships where the parent's other child is involved
-			tx = pr.db.Debug().Where(
-				"(parent_id = ? AND child_id != ?) OR (parent_id = ? AND child_id != ?)", r.ParentID, childID, childID, r.ParentID,
-			).Find(&rdChilds)
-			if tx.Error != nil {
-				return nil, tx.Error
-			}
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		tx := db.Delete(&domain.Relationship{}, "id = ?", id)
+		rows = tx.RowsAffected
 
-			rs = append(rs, rdChilds...)
-		}
+		return tx.Error
+	})
+	if err != nil {
+		return err
 	}
 
-	return rs, nil
-}
-
-// CheckIncestuousOffspring checks if there are any incestuous relationships in a given slice.
-func (pr *PostgresRepository) checkIncestuousOffspring(relationships []*domain.Relationship) bool {
-	for i, r1 := range relationships {
-		for _, r2 := range relationships[i+1:] {
-			if (r1.ParentID == r2.ParentID && r1.ChildID == r2.ChildID) || (r1.ParentID == r2.ChildID && r1.ChildID == r2.ParentID) {
-				return true
-			} else if r1.ParentID != r2.ParentID {
-				// Only list parents if there's a potential incestuous relationship
-				p1, err := pr.listParentsByID(r1.ParentID)
-				if err != nil {
-					pr.log.Error(fmt.Sprintf("Error while getting parents for ID: %s", r1.ParentID), zap.Error(err))
-					return false
-				}
-				p2, err := pr.listParentsByID(r2.ParentID)
-				if err != nil {
-					pr.log.Error(fmt.Sprintf("Error while getting parents for ID: %s", r2.ParentID), zap.Error(err))
-					return false
-				}
-				for _, pp1 := range p1 {
-					for _, pp2 := range p2 {
-						if pp1.ID == pp2.ID {
-							return true
-						}
-					}
-				}
-			}
-		}
+	if rows == 0 {
+		return app.ErrRelationshipNotFound
 	}
-	return false
+
+	return nil
 }
@@ -3,6 +3,11 @@
 package adapter
 
 import (
+	"context"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 )
@@ -22,3 +27,34 @@ type PostgresRepository struct {
 func NewPostgresRepository(db *gorm.DB, log *zap.Logger) *PostgresRepository {
 	return &PostgresRepository{db, log}
 }
+
+// qSetAuditor hands who makes the changes of a transaction over to the
+// triggers writing the audit log, for the rest of the transaction.
+const qSetAuditor = `
+	SELECT set_config('familytree.actor', @actor, true),
+		set_config('familytree.request_id', @request, true)`
+
+// Transaction runs fn within a database transaction, handing it a
+// repository bound to that transaction. Everything fn did is rolled
+// back if it returns an error.
+func (pr *PostgresRepository) Transaction(ctx context.Context, fn func(app.Repository) error) error {
+	return pr.audited(ctx, func(tx *gorm.DB) error {
+		return fn(&PostgresRepository{tx, pr.log})
+	})
+}
+
+// audited runs fn within a database transaction, or a savepoint of the one
+// running, in which every change is recorded in the audit log, by triggers,
+// along with the actor and request found in the context.
+func (pr *PostgresRepository) audited(ctx context.Context, fn func(*gorm.DB) error) error {
+	a := domain.AuditorFromContext(ctx)
+
+	return pr.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		args := map[string]interface{}{"actor": a.Actor, "request": a.RequestID}
+		if err := tx.Exec(qSetAuditor, args).Error; err != nil {
+			return err
+		}
+
+		return fn(tx)
+	})
+}
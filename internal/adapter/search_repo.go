@@ -0,0 +1,149 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// qSearchPeople ranks the people matching a search by adding up how alike
+// their name and the query are as trigrams, how well the words of their
+// names match those of the query, and the share of the phonetic codes of
+// the query their names sound like. A person matching any of the three is
+// found, unless in the trash, and @from and @to limit their year of birth
+// unless zero.
+const qSearchPeople = `
+	SELECT p.id,
+		similarity(p.name, @query)
+		+ ts_rank(p.search_vector, plainto_tsquery('simple', @query))
+		+ CAST(cardinality(ARRAY(
+			SELECT unnest(string_to_array(p.phonetic, ' '))
+			INTERSECT
+			SELECT unnest(string_to_array(@codes, ' '))
+		)) AS float) / GREATEST(cardinality(string_to_array(@codes, ' ')), 1) AS score
+	FROM people p
+	WHERE p.deleted_at IS NULL
+	AND (p.name % @query
+		OR p.search_vector @@ plainto_tsquery('simple', @query)
+		OR string_to_array(p.phonetic, ' ') && string_to_array(@codes, ' '))
+	AND (@from = 0 OR CAST(substring(p.birth_date from '[0-9]{4}') AS integer) >= @from)
+	AND (@to = 0 OR CAST(substring(p.birth_date from '[0-9]{4}') AS integer) <= @to)
+	ORDER BY score DESC, p.name, p.id
+	LIMIT @limit`
+
+// SearchPeople returns the people matching a search, the best match first.
+func (pr *PostgresRepository) SearchPeople(ctx context.Context, s domain.PersonSearch) ([]*domain.SearchResult, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "SearchPeople")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var rows []struct {
+		ID    string
+		Score float64
+	}
+
+	args := map[string]interface{}{
+		"query": s.Query,
+		"codes": strings.Join(s.Codes, " "),
+		"from":  s.BornFrom,
+		"to":    s.BornTo,
+		"limit": s.Limit,
+	}
+
+	err := pr.db.WithContext(ctx).Raw(qSearchPeople, args).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(rows))
+	for _, r := range rows {
+		ids = append(ids, r.ID)
+	}
+
+	people, err := pr.ListPeopleByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[string]*domain.Person, len(people))
+	for _, p := range people {
+		byID[p.ID] = p
+	}
+
+	results := make([]*domain.SearchResult, 0, len(rows))
+
+	for _, r := range rows {
+		if p, ok := byID[r.ID]; ok {
+			results = append(results, &domain.SearchResult{Person: p, Score: r.Score})
+		}
+	}
+
+	return results, nil
+}
+
+// ListPeopleWithoutPhonetic returns up to limit people, those in the trash
+// included, with no phonetic codes, ordered by ID and following the person
+// with the given ID, if any.
+func (pr *PostgresRepository) ListPeopleWithoutPhonetic(ctx context.Context, after string, limit int) (
+	[]*domain.Person, error,
+) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListPeopleWithoutPhonetic")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var p []*domain.Person
+
+	tx := pr.db.WithContext(ctx).Unscoped().Where("phonetic = ''")
+
+	if after != "" {
+		tx = tx.Where("id > ?", after)
+	}
+
+	err := tx.Order("id").Limit(limit).Find(&p).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// UpdatePhonetic stores the phonetic codes of people, even those in the trash,
+// in a single statement. Codes maps the ID of each person to their codes.
+func (pr *PostgresRepository) UpdatePhonetic(ctx context.Context, codes map[string]string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "UpdatePhonetic")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	if len(codes) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(codes))
+	args := make([]interface{}, 0, 2*len(codes))
+
+	for id, c := range codes {
+		values = append(values, "(CAST(? AS uuid), ?)")
+		args = append(args, id, c)
+	}
+
+	q := `UPDATE people SET phonetic = v.codes FROM (VALUES ` + strings.Join(values, ", ") +
+		`) AS v(id, codes) WHERE people.id = v.id`
+
+	return pr.db.WithContext(ctx).Exec(q, args...).Error
+}
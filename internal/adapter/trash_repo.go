@@ -0,0 +1,310 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"gorm.io/gorm"
+)
+
+// qTrashedFamily lists a person in the trash, deleted at @at, and the
+// descendants deleted along with them, through the relationships deleted
+// at the same time.
+const qTrashedFamily = `
+	WITH RECURSIVE family AS (
+		SELECT id FROM people WHERE id = @id AND deleted_at = @at
+		UNION
+		SELECT r.child_id
+		FROM relationships r
+		JOIN family f ON r.parent_id = f.id
+		JOIN people c ON r.child_id = c.id
+		WHERE r.deleted_at = @at
+		AND c.deleted_at = @at
+	)
+	SELECT id FROM family`
+
+// whereRelationshipEndsAlive and wherePartnersAlive keep the relationships
+// and partnerships between people out of the trash only.
+const (
+	whereRelationshipEndsAlive = `NOT EXISTS (
+		SELECT 1 FROM people p
+		WHERE p.id IN (relationships.parent_id, relationships.child_id)
+		AND p.deleted_at IS NOT NULL
+	)`
+	wherePartnersAlive = `NOT EXISTS (
+		SELECT 1 FROM people p
+		WHERE p.id IN (partnerships.person1_id, partnerships.person2_id)
+		AND p.deleted_at IS NOT NULL
+	)`
+)
+
+// ListTrash returns the people in the trash, and the relationships deleted on
+// their own, between people out of it, the latest deleted first.
+func (pr *PostgresRepository) ListTrash(ctx context.Context) (*domain.Trash, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListTrash")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var (
+		people []*domain.Person
+		rs     []*domain.Relationship
+	)
+
+	tx := pr.db.WithContext(ctx).Unscoped()
+
+	err := tx.Where("deleted_at IS NOT NULL").Order("deleted_at DESC, id").Find(&people).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Where("deleted_at IS NOT NULL").Where(whereRelationshipEndsAlive).
+		Order("deleted_at DESC, id").Find(&rs).Error
+	if err != nil {
+		return nil, err
+	}
+
+	t := &domain.Trash{
+		People:        make([]*domain.TrashedPerson, len(people)),
+		Relationships: make([]*domain.TrashedRelationship, len(rs)),
+	}
+
+	for i, p := range people {
+		t.People[i] = &domain.TrashedPerson{Person: p, DeletedAt: p.DeletedAt.Time}
+	}
+
+	for i, r := range rs {
+		t.Relationships[i] = &domain.TrashedRelationship{Relationship: r, DeletedAt: r.DeletedAt.Time}
+	}
+
+	return t, nil
+}
+
+// RestorePerson takes a person out of the trash along with what was deleted
+// with them: their descendants, and the relationships and partnerships
+// between any of them and people out of the trash. It is meant to run
+// within a transaction.
+func (pr *PostgresRepository) RestorePerson(ctx context.Context, id string) (*domain.DeleteReport, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "RestorePerson")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	db := pr.db.WithContext(ctx)
+
+	var p domain.Person
+
+	err := db.Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&p).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, app.ErrPersonNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	at := p.DeletedAt.Time
+	report := &domain.DeleteReport{}
+
+	err = db.Raw(qTrashedFamily, map[string]interface{}{"id": id, "at": at}).Scan(&report.People).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = pr.audited(ctx, func(db *gorm.DB) error {
+		return restoreFamily(db.Unscoped(), report, at)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// restoreFamily takes the people of a report out of the trash, along with the
+// relationships and partnerships deleted at the same time between any of
+// them and people out of the trash, adding those to the report.
+func restoreFamily(db *gorm.DB, report *domain.DeleteReport, at time.Time) error {
+	ids := report.People
+
+	err := db.Model(&domain.Person{}).Where("id IN ?", ids).Update("deleted_at", nil).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Model(&domain.Relationship{}).
+		Where("deleted_at = ? AND (parent_id IN ? OR child_id IN ?)", at, ids, ids).
+		Where(whereRelationshipEndsAlive).
+		Pluck("id", &report.Relationships).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Model(&domain.Partnership{}).
+		Where("deleted_at = ? AND (person1_id IN ? OR person2_id IN ?)", at, ids, ids).
+		Where(wherePartnersAlive).
+		Pluck("id", &report.Partnerships).Error
+	if err != nil {
+		return err
+	}
+
+	if len(report.Relationships) > 0 {
+		err = db.Model(&domain.Relationship{}).Where("id IN ?", report.Relationships).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(report.Partnerships) > 0 {
+		err = db.Model(&domain.Partnership{}).Where("id IN ?", report.Partnerships).Update("deleted_at", nil).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PurgePerson deletes a person in the trash for good, along with every
+// relationship and partnership they were part of.
+// It is meant to run within a transaction.
+func (pr *PostgresRepository) PurgePerson(ctx context.Context, id string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "PurgePerson")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	db := pr.db.WithContext(ctx)
+
+	var n int64
+
+	err := db.Unscoped().Model(&domain.Person{}).Where("id = ? AND deleted_at IS NOT NULL", id).Count(&n).Error
+	if err != nil {
+		return err
+	}
+
+	if n == 0 {
+		return app.ErrPersonNotFound
+	}
+
+	return pr.audited(ctx, func(db *gorm.DB) error {
+		return purgePeople(db, []string{id})
+	})
+}
+
+// GetDeletedRelationship returns a relationship in the trash.
+func (pr *PostgresRepository) GetDeletedRelationship(ctx context.Context, id string) (*domain.Relationship, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "GetDeletedRelationship")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var r domain.Relationship
+
+	err := pr.db.WithContext(ctx).Unscoped().Where("id = ? AND deleted_at IS NOT NULL", id).First(&r).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, app.ErrRelationshipNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// RestoreRelationship takes a relationship out of the trash.
+func (pr *PostgresRepository) RestoreRelationship(ctx context.Context, id string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "RestoreRelationship")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var rows int64
+
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		tx := db.Unscoped().Model(&domain.Relationship{}).
+			Where("id = ? AND deleted_at IS NOT NULL", id).
+			Update("deleted_at", nil)
+		rows = tx.RowsAffected
+
+		return tx.Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return app.ErrRelationshipNotFound
+	}
+
+	return nil
+}
+
+// PurgeRelationship deletes a relationship in the trash for good.
+func (pr *PostgresRepository) PurgeRelationship(ctx context.Context, id string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "PurgeRelationship")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var rows int64
+
+	err := pr.audited(ctx, func(db *gorm.DB) error {
+		tx := db.Unscoped().Delete(&domain.Relationship{}, "id = ? AND deleted_at IS NOT NULL", id)
+		rows = tx.RowsAffected
+
+		return tx.Error
+	})
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return app.ErrRelationshipNotFound
+	}
+
+	return nil
+}
+
+// purgePeople deletes people for good, in the trash or not, along with every
+// relationship and partnership they were part of.
+func purgePeople(db *gorm.DB, ids []string) error {
+	db = db.Unscoped()
+
+	err := db.Where("parent_id IN ? OR child_id IN ?", ids, ids).Delete(&domain.Relationship{}).Error
+	if err != nil {
+		return err
+	}
+
+	err = db.Where("person1_id IN ? OR person2_id IN ?", ids, ids).Delete(&domain.Partnership{}).Error
+	if err != nil {
+		return err
+	}
+
+	return db.Where("id IN ?", ids).Delete(&domain.Person{}).Error
+}
@@ -3,6 +3,7 @@ package app
 
 import (
 	"context"
+	"time"
 
 	"github.com/bhborges/family-tree-api/internal/domain"
 
@@ -16,24 +17,50 @@ const _SegmentPrefix = "familytree:application"
 type Application struct {
 	repository Repository
 	log        *zap.Logger
+	config     *Config
 }
 
 // Repository specifies the signature of a person repository.
 type Repository interface {
-	ListPeople(context.Context) ([]*domain.Person, error)
-	ListRelationships(context.Context) ([]*domain.Relationship, error)
+	ListPeople(context.Context, domain.PeopleQuery) ([]*domain.Person, error)
+	ListPeopleByIDs(context.Context, []string) ([]*domain.Person, error)
+	SearchPeople(context.Context, domain.PersonSearch) ([]*domain.SearchResult, error)
+	ListRelationships(context.Context, domain.RelationshipFilter) ([]*domain.Relationship, error)
+	ListRelationshipsByPersonIDs(context.Context, []string) ([]*domain.Relationship, error)
+	GetRelationshipByID(context.Context, string) (*domain.Relationship, error)
 	GetPersonByID(context.Context, string) (*domain.Person, error)
 	CreatePerson(context.Context, domain.Person) (string, error)
 	CreatePeople(context.Context, []domain.Person) ([]string, error)
 	UpdatePerson(context.Context, domain.Person) error
-	DeletePerson(context.Context, string) error
+	DeletePeople(context.Context, []string) error
 	CreateRelationship(context.Context, domain.Relationship) (string, error)
+	ConsanguinityDegree(context.Context, string, string, int) (int, bool, error)
 	UpdateRelationship(context.Context, *domain.Relationship) error
 	DeleteRelationship(context.Context, string) error
-	BuildFamilyTree(context.Context, string) (*domain.FamilyTree, error)
+	ListPartnerships(context.Context) ([]*domain.Partnership, error)
+	ListPartnershipsByPersonIDs(context.Context, []string) ([]*domain.Partnership, error)
+	GetPartnershipByID(context.Context, string) (*domain.Partnership, error)
+	CreatePartnership(context.Context, domain.Partnership) (string, error)
+	UpdatePartnership(context.Context, *domain.Partnership) error
+	DeletePartnership(context.Context, string) error
+	BuildFamilyTree(context.Context, string, domain.TreeOptions) (*domain.FamilyTree, error)
+	ListDuplicateCandidates(context.Context, domain.DuplicateQuery) ([]*domain.DuplicateCandidate, error)
+	MergePerson(context.Context, *domain.PersonMerge) error
+	ListTrash(context.Context) (*domain.Trash, error)
+	RestorePerson(context.Context, string) (*domain.DeleteReport, error)
+	PurgePerson(context.Context, string) error
+	GetDeletedRelationship(context.Context, string) (*domain.Relationship, error)
+	RestoreRelationship(context.Context, string) error
+	PurgeRelationship(context.Context, string) error
+	ListPersonMerges(context.Context, string) ([]*domain.PersonMerge, error)
+	ListPersonHistory(context.Context, string) ([]*domain.AuditEntry, error)
+	ListPeopleWithoutPhonetic(context.Context, string, int) ([]*domain.Person, error)
+	UpdatePhonetic(context.Context, map[string]string) error
+	Transaction(context.Context, func(Repository) error) error
+	AsOf(context.Context, time.Time, string, func(Repository) error) error
 }
 
 // NewApplication initializes an instance of a person Application.
-func NewApplication(repository Repository, log *zap.Logger) *Application {
-	return &Application{repository, log}
+func NewApplication(repository Repository, log *zap.Logger, config *Config) *Application {
+	return &Application{repository, log, config}
 }
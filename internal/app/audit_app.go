@@ -0,0 +1,37 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// PersonHistory returns the changes made to a person and to the
+// relationships and partnerships they were part of, oldest first, as
+// recorded in the audit log. The history of a person deleted, even for
+// good, is kept.
+func (a *Application) PersonHistory(ctx context.Context, id string) ([]*domain.AuditEntry, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "PersonHistory")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	entries, err := a.repository.ListPersonHistory(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entries) == 0 {
+		if _, err := a.repository.GetPersonByID(ctx, id); err != nil {
+			return nil, err
+		}
+	}
+
+	return entries, nil
+}
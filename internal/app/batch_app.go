@@ -0,0 +1,164 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// CreateFamilyBatch creates the people of a batch and then its relationships,
+// resolving the temporary keys they refer to, all in a single transaction.
+// Either everything is created or, wrapped in a BatchError naming the field
+// and index of the item, the error rejecting the first item that could not
+// be is returned and nothing is.
+func (a *Application) CreateFamilyBatch(ctx context.Context, b domain.FamilyBatch) (*domain.FamilyBatchResult, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "CreateFamilyBatch")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	people := make([]domain.Person, len(b.People))
+	keys := make(map[string]int, len(b.People))
+
+	for i, bp := range b.People {
+		if bp.Key != "" {
+			if _, ok := keys[bp.Key]; ok {
+				return nil, &BatchError{Field: "people", Index: i, Err: ErrDuplicateBatchKey}
+			}
+
+			keys[bp.Key] = i
+		}
+
+		people[i] = bp.Person
+		if people[i].Sex == "" {
+			people[i].Sex = domain.SexUnknown
+		}
+
+		if err := validatePerson(&people[i]); err != nil {
+			return nil, &BatchError{Field: "people", Index: i, Err: err}
+		}
+	}
+
+	res := &domain.FamilyBatchResult{
+		Keys:          make(map[string]string, len(keys)),
+		Relationships: make([]string, len(b.Relationships)),
+	}
+
+	err := a.repository.Transaction(ctx, func(tx Repository) error {
+		ids, err := tx.CreatePeople(ctx, people)
+		if err != nil {
+			return err
+		}
+
+		res.People = ids
+
+		for k, i := range keys {
+			res.Keys[k] = ids[i]
+		}
+
+		known, err := batchPeople(ctx, tx, b.Relationships, res.Keys)
+		if err != nil {
+			return err
+		}
+
+		for i, dr := range b.Relationships {
+			if dr, err = resolveBatchKeys(dr, res.Keys, known); err != nil {
+				return &BatchError{Field: "relationships", Index: i, Err: err}
+			}
+
+			if dr.Type == "" {
+				dr.Type = domain.RelationshipBiological
+			}
+
+			if err := a.validateRelationship(ctx, tx, dr, ""); err != nil {
+				return &BatchError{Field: "relationships", Index: i, Err: err}
+			}
+
+			id, err := tx.CreateRelationship(ctx, dr)
+			if err != nil {
+				return &BatchError{Field: "relationships", Index: i, Err: err}
+			}
+
+			res.Relationships[i] = id
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// batchPeople looks up, in a single query, the people already recorded that
+// the relationships of a batch refer to by ID rather than by temporary key.
+func batchPeople(
+	ctx context.Context, repo Repository, drs []domain.Relationship, keys map[string]string,
+) (map[string]bool, error) {
+	ids := make([]string, 0)
+
+	for _, dr := range drs {
+		for _, ref := range []string{dr.ParentID, dr.ChildID} {
+			if _, ok := keys[ref]; !ok && isUUID(ref) {
+				ids = append(ids, ref)
+			}
+		}
+	}
+
+	people, err := repo.ListPeopleByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	known := make(map[string]bool, len(people))
+	for _, p := range people {
+		known[p.ID] = true
+	}
+
+	return known, nil
+}
+
+// resolveBatchKeys replaces the temporary keys a relationship refers
+// to with the IDs of their people, keeping the IDs of known people.
+func resolveBatchKeys(dr domain.Relationship, keys map[string]string, known map[string]bool) (domain.Relationship, error) {
+	for _, ref := range []*string{&dr.ParentID, &dr.ChildID} {
+		if id, ok := keys[*ref]; ok {
+			*ref = id
+
+			continue
+		}
+
+		if !known[*ref] {
+			return dr, fmt.Errorf("%w: %q", ErrUnknownBatchKey, *ref)
+		}
+	}
+
+	return dr, nil
+}
+
+// isUUID reports whether s is written as a UUID, such as the IDs of people.
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i, c := range s {
+		switch {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return false
+			}
+		case (c < '0' || c > '9') && (c < 'a' || c > 'f') && (c < 'A' || c > 'F'):
+			return false
+		}
+	}
+
+	return true
+}
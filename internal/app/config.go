@@ -0,0 +1,59 @@
+package app
+
+import (
+	"github.com/kelseyhightower/envconfig"
+	"go.uber.org/zap"
+)
+
+// ConsanguinityPolicy tells what happens when a child
+// is given two parents who are blood relatives.
+type ConsanguinityPolicy string
+
+const (
+	// ConsanguinityOff does not check whether parents are related.
+	ConsanguinityOff ConsanguinityPolicy = "off"
+	// ConsanguinityWarn records the relationship but logs a warning.
+	ConsanguinityWarn ConsanguinityPolicy = "warn"
+	// ConsanguinityReject refuses the relationship with ErrIncestuousOffspring.
+	ConsanguinityReject ConsanguinityPolicy = "reject"
+)
+
+// Config holds the settings of the application, read from
+// FAMILYTREE_ prefixed environment variables.
+//
+// Consanguinity.MaxDegree is the largest degree of kinship, counted as the
+// generations from each parent up to their closest common ancestor, at which
+// parents are considered too closely related: 1 for a parent and their child,
+// 2 for siblings or a grandparent and grandchild, 4 for first cousins.
+type Config struct {
+	Consanguinity struct {
+		Policy    ConsanguinityPolicy `split_words:"true" required:"false" default:"reject"`
+		MaxDegree int                 `split_words:"true" required:"false" default:"2"`
+	}
+}
+
+// ProvideConfig process the configuration needed to run the application.
+func ProvideConfig(l *zap.Logger) (*Config, error) {
+	var config Config
+	if err := envconfig.Process("familytree", &config); err != nil {
+		l.Error(ErrEnvConfig.Error(), zap.Error(err))
+
+		return nil, ErrEnvConfig
+	}
+
+	switch config.Consanguinity.Policy {
+	case ConsanguinityOff, ConsanguinityWarn, ConsanguinityReject:
+	default:
+		l.Error(ErrEnvConfig.Error(), zap.String("policy", string(config.Consanguinity.Policy)))
+
+		return nil, ErrEnvConfig
+	}
+
+	if config.Consanguinity.MaxDegree < 1 {
+		l.Error(ErrEnvConfig.Error(), zap.Int("maxDegree", config.Consanguinity.MaxDegree))
+
+		return nil, ErrEnvConfig
+	}
+
+	return &config, nil
+}
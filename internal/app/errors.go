@@ -1,6 +1,9 @@
 package app
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrPersonNotFound occurs when a person is not found.
@@ -9,6 +12,99 @@ var (
 	ErrNoRowsInserted       = errors.New("no rows delete")
 	ErrNoRowsUpdated        = errors.New("no rows delete")
 
+	// ErrInvalidInclude occurs when a person is read along with relatives of an unknown kind.
+	ErrInvalidInclude = errors.New("invalid include")
+
+	// ErrInvalidDeletePolicy occurs when a person is deleted under a policy that is not supported.
+	ErrInvalidDeletePolicy = errors.New("invalid delete policy")
+	// ErrPersonHasRelatives occurs when a person with relationships or partnerships
+	// is deleted under the restrict policy.
+	ErrPersonHasRelatives = errors.New("the person has relationships or partnerships")
+
+	// ErrInvalidSex occurs when a person is given a sex that is not supported.
+	ErrInvalidSex = errors.New("invalid sex")
+
+	// ErrInvalidRelationshipType occurs when a relationship is given a type that is not supported.
+	ErrInvalidRelationshipType = errors.New("invalid relationship type")
+
+	// ErrSelfParenting occurs when a person is made their own parent.
+	ErrSelfParenting = errors.New("a person cannot be their own parent")
+	// ErrRelationshipCycle occurs when a child would become an ancestor of their own parent.
+	ErrRelationshipCycle = errors.New("the child is already an ancestor of the parent")
+	// ErrTooManyParents occurs when a child would have more than two biological parents.
+	ErrTooManyParents = errors.New("the child already has two biological parents")
+	// ErrDuplicateRelationship occurs when a parent and child are already related.
+	ErrDuplicateRelationship = errors.New("the relationship already exists")
+
+	// ErrPartnershipNotFound occurs when a partnership is not found.
+	ErrPartnershipNotFound = errors.New("partnership not found")
+	// ErrInvalidPartnership occurs when a partnership is not between two different people,
+	// or is given a type or end reason that is not supported.
+	ErrInvalidPartnership = errors.New("invalid partnership")
+
+	// ErrPeopleNotConnected occurs when no chain of relationships links two people.
+	ErrPeopleNotConnected = errors.New("people are not connected")
+	// ErrInvalidTreeMode occurs when a family tree is requested in an unknown mode.
+	ErrInvalidTreeMode = errors.New("invalid family tree mode")
+	// ErrInvalidGenerations occurs when a family tree is limited to a negative number of generations,
+	// or to more than MaxGenerations.
+	ErrInvalidGenerations = errors.New("invalid number of generations")
+	// ErrInvalidGEDCOM occurs when an imported file is not valid GEDCOM.
+	ErrInvalidGEDCOM = errors.New("invalid GEDCOM file")
+	// ErrNoSharedAncestor occurs when two people have no ancestor in common.
+	ErrNoSharedAncestor = errors.New("people share no ancestor")
+	// ErrNotCousins occurs when two people are related but not as cousins.
+	ErrNotCousins = errors.New("people are not cousins")
+
+	// ErrDuplicateBatchKey occurs when a temporary key is given to more than one person of a batch.
+	ErrDuplicateBatchKey = errors.New("temporary key given to more than one person")
+	// ErrUnknownBatchKey occurs when a relationship of a batch refers to neither
+	// a temporary key of the batch nor a person already recorded.
+	ErrUnknownBatchKey = errors.New("unknown temporary key or person")
+
+	// ErrInvalidSort occurs when people are listed in an unknown order.
+	ErrInvalidSort = errors.New("invalid sort order")
+	// ErrInvalidLimit occurs when a page is limited to a number of people out of range.
+	ErrInvalidLimit = errors.New("invalid limit")
+
+	// ErrInvalidSearch occurs when a search has no words to look for,
+	// or a range of birth years that ends before it starts.
+	ErrInvalidSearch = errors.New("invalid search")
+
+	// ErrInvalidMerge occurs when a person is merged with themselves,
+	// or with one of their ancestors or descendants.
+	ErrInvalidMerge = errors.New("people cannot be merged")
+	// ErrInvalidScore occurs when duplicate candidates are asked for with a negative score.
+	ErrInvalidScore = errors.New("invalid score")
+
+	// ErrInvalidAsOf occurs when a family tree is asked for as it will be, as it was before
+	// the audit log started, or at an unreadable moment.
+	ErrInvalidAsOf = errors.New("invalid asOf")
+
+	// ErrEnvConfig is returned if some error occurs setting up the environment vars.
+	ErrEnvConfig = errors.New("familytree: unable to setup environment variables")
+
 	// IncestuousOffspring practice not advisable, only for didactic purposes.
 	ErrIncestuousOffspring = errors.New("this relationship is not allowed")
 )
+
+// BatchError wraps the error rejecting an item of a batch created in
+// atomic mode, along with the index of that item and, for batches of
+// several kinds of items, the field holding it.
+type BatchError struct {
+	Field string
+	Index int
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("%s[%d]: %s", e.Field, e.Index, e.Err)
+	}
+
+	return fmt.Sprintf("item %d: %s", e.Index, e.Err)
+}
+
+func (e *BatchError) Unwrap() error {
+	return e.Err
+}
@@ -3,14 +3,21 @@ package app
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/bhborges/family-tree-api/internal/domain"
 
 	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
+// MaxGenerations is the largest number of generations a family tree spans
+// away from its root person, and what no limit comes down to.
+const MaxGenerations = 64
+
 // BuildFamilyTree return family tree of person.
-func (a *Application) BuildFamilyTree(ctx context.Context, id string) (*domain.FamilyTree, error) {
+// An empty mode defaults to the person's ancestors.
+// Given a moment, the tree is built as it was then.
+func (a *Application) BuildFamilyTree(ctx context.Context, id string, opts domain.TreeOptions) (*domain.FamilyTree, error) {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
 		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "BuildFamilyTree")
@@ -19,10 +26,216 @@ func (a *Application) BuildFamilyTree(ctx context.Context, id string) (*domain.F
 		defer segment.End()
 	}
 
-	t, err := a.repository.BuildFamilyTree(ctx, id)
+	switch opts.Mode {
+	case "":
+		opts.Mode = domain.TreeModeAncestors
+	case domain.TreeModeAncestors, domain.TreeModeDescendants, domain.TreeModeHourglass:
+	default:
+		return nil, ErrInvalidTreeMode
+	}
+
+	switch {
+	case opts.Generations < 0 || opts.Generations > MaxGenerations:
+		return nil, ErrInvalidGenerations
+	case opts.Generations == 0:
+		opts.Generations = MaxGenerations
+	}
+
+	if !opts.AsOf.IsZero() {
+		return a.buildFamilyTreeAsOf(ctx, id, opts)
+	}
+
+	t, err := a.repository.BuildFamilyTree(ctx, id, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := a.GetPersonByID(ctx, id, domain.PersonIncludeSiblings, domain.PersonIncludeSpouse)
 	if err != nil {
 		return nil, err
 	}
 
+	addKin(t, p)
+
+	if err := a.addPartnerships(ctx, t); err != nil {
+		return nil, err
+	}
+
+	for _, m := range t.Members {
+		m.SortRelationships()
+	}
+
 	return t, nil
 }
+
+// buildFamilyTreeAsOf builds a family tree as it was at the moment the
+// options tell, from the family of the person as it was then.
+func (a *Application) buildFamilyTreeAsOf(ctx context.Context, id string, opts domain.TreeOptions) (*domain.FamilyTree, error) {
+	if opts.AsOf.After(time.Now()) {
+		return nil, ErrInvalidAsOf
+	}
+
+	var t *domain.FamilyTree
+
+	err := a.repository.AsOf(ctx, opts.AsOf, id, func(repo Repository) error {
+		past := &Application{repo, a.log, a.config}
+		opts.AsOf = time.Time{}
+
+		var err error
+
+		t, err = past.BuildFamilyTree(ctx, id, opts)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// BaconNumber returns the degree of separation between two people
+// and the chain of relatives connecting them.
+func (a *Application) BaconNumber(ctx context.Context, id1, id2 string) (*domain.BaconNumber, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "BaconNumber")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	p1, err := a.repository.GetPersonByID(ctx, id1)
+	if err != nil {
+		return nil, err
+	}
+
+	p2, err := a.repository.GetPersonByID(ctx, id2)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := newFamilyGraph(a.repository).shortestPath(ctx, p1.ID, p2.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ids == nil {
+		return nil, ErrPeopleNotConnected
+	}
+
+	people, err := a.repository.ListPeopleByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	n := len(ids) - 1
+	p2.BaconNumber = n
+
+	return &domain.BaconNumber{
+		Person1: *p1,
+		Person2: *p2,
+		Number:  n,
+		Path:    orderPeople(people, ids),
+	}, nil
+}
+
+// addKin lists the siblings of a person among the relationships of their
+// own member in the family tree, along with the spouse inferred from shared
+// children when they have no recorded partnership.
+func addKin(t *domain.FamilyTree, p *domain.Person) {
+	var root *domain.Member
+
+	for _, m := range t.Members {
+		if m.ID == p.ID {
+			root = m
+
+			break
+		}
+	}
+
+	if root == nil {
+		return
+	}
+
+	for _, s := range p.Siblings {
+		root.Relationships = append(root.Relationships, domain.FamilyRelationship{
+			ID: s.ID, Name: s.Name, Relationship: "sibling",
+		})
+	}
+
+	for _, s := range p.HalfSiblings {
+		root.Relationships = append(root.Relationships, domain.FamilyRelationship{
+			ID: s.ID, Name: s.Name, Relationship: "half-sibling",
+		})
+	}
+
+	for _, s := range p.StepSiblings {
+		root.Relationships = append(root.Relationships, domain.FamilyRelationship{
+			ID: s.ID, Name: s.Name, Relationship: "step-sibling",
+		})
+	}
+
+	if p.Spouse != nil && len(p.Partnerships) == 0 {
+		root.Relationships = append(root.Relationships, domain.FamilyRelationship{
+			ID: p.Spouse.ID, Name: p.Spouse.Name, Relationship: "spouse",
+		})
+	}
+}
+
+// addPartnerships lists the partners of every member of a family tree among
+// their relationships, whether the partners are members themselves or not.
+func (a *Application) addPartnerships(ctx context.Context, t *domain.FamilyTree) error {
+	members := make(map[string]*domain.Member, len(t.Members))
+	ids := make([]string, 0, len(t.Members))
+
+	for _, m := range t.Members {
+		members[m.ID] = m
+		ids = append(ids, m.ID)
+	}
+
+	ps, err := a.repository.ListPartnershipsByPersonIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[string]string, len(t.Members))
+	missing := make([]string, 0)
+
+	for _, m := range t.Members {
+		names[m.ID] = m.Name
+	}
+
+	for _, p := range ps {
+		for _, id := range []string{p.Person1ID, p.Person2ID} {
+			if _, ok := names[id]; !ok && !contains(missing, id) {
+				missing = append(missing, id)
+			}
+		}
+	}
+
+	people, err := a.repository.ListPeopleByIDs(ctx, missing)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range people {
+		names[p.ID] = p.Name
+	}
+
+	for _, p := range ps {
+		for _, id := range []string{p.Person1ID, p.Person2ID} {
+			m, ok := members[id]
+			if !ok {
+				continue
+			}
+
+			partner := p.Partner(id)
+			m.Relationships = append(m.Relationships, domain.FamilyRelationship{
+				ID: partner, Name: names[partner], Relationship: partnerTerm(p),
+			})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,495 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+	"github.com/bhborges/family-tree-api/pkg/gedcom"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// gedcomIgnoredRecords lists the level zero records that carry
+// nothing to import and are skipped without a warning.
+//
+//nolint:gochecknoglobals
+var gedcomIgnoredRecords = map[string]bool{
+	"HEAD": true, "TRLR": true, "SUBM": true, "SUBN": true,
+	"SOUR": true, "REPO": true, "NOTE": true, "OBJE": true,
+}
+
+// gedcomSexes maps the values of the SEX tag to the sex of a person.
+//
+//nolint:gochecknoglobals
+var gedcomSexes = map[string]domain.Sex{
+	"M": domain.SexMale, "F": domain.SexFemale, "X": domain.SexOther, "U": domain.SexUnknown,
+}
+
+// gedcomPedigrees maps the values of the PEDI tag to relationship types.
+//
+//nolint:gochecknoglobals
+var gedcomPedigrees = map[string]domain.RelationshipType{
+	"birth": domain.RelationshipBiological, "adopted": domain.RelationshipAdoptive,
+	"foster": domain.RelationshipFoster, "step": domain.RelationshipStep,
+}
+
+// gedcomIndividual is a person read from an INDI record.
+type gedcomIndividual struct {
+	xref   string
+	person domain.Person
+}
+
+// gedcomLineage is a relationship read from a FAM record,
+// along with the records it was read from.
+type gedcomLineage struct {
+	fam, parent, child *gedcom.Record
+	relationship       domain.Relationship
+}
+
+// ImportGEDCOM creates the people and relationships described by the INDI
+// and FAM records of a GEDCOM file, all in a single transaction. Records that
+// cannot be fully imported are reported as warnings instead of failing it.
+func (a *Application) ImportGEDCOM(ctx context.Context, r io.Reader) (*domain.ImportReport, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ImportGEDCOM")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	rs, err := gedcom.Parse(r)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidGEDCOM, err)
+	}
+
+	report := &domain.ImportReport{
+		People:   make(map[string]string),
+		Warnings: make([]domain.ImportWarning, 0),
+	}
+
+	indis := make([]gedcomIndividual, 0)
+	fams := make([]*gedcom.Record, 0)
+	xrefs := make(map[string]bool)
+	pedigrees := make(map[[2]string]domain.RelationshipType)
+
+	for _, rec := range rs {
+		switch {
+		case rec.Tag == "HEAD":
+			if cs := strings.ToUpper(rec.ValueOf("CHAR")); cs != "" && cs != "UTF-8" && cs != "ASCII" {
+				report.Warnings = append(report.Warnings, domain.ImportWarning{
+					Line: rec.Line, Message: fmt.Sprintf("character set %s is read as UTF-8", cs),
+				})
+			}
+		case rec.Tag == "INDI":
+			if rec.XRef == "" || xrefs[rec.XRef] {
+				report.Warnings = append(report.Warnings, domain.ImportWarning{
+					XRef: rec.XRef, Line: rec.Line, Message: "individual skipped for lacking a unique identifier",
+				})
+
+				continue
+			}
+
+			xrefs[rec.XRef] = true
+			indis = append(indis, gedcomIndividual{rec.XRef, gedcomPerson(rec, report)})
+
+			for _, famc := range rec.All("FAMC") {
+				if t, ok := gedcomPedigrees[strings.ToLower(famc.ValueOf("PEDI"))]; ok {
+					pedigrees[[2]string{rec.XRef, famc.Value}] = t
+				}
+			}
+		case rec.Tag == "FAM":
+			fams = append(fams, rec)
+		case !gedcomIgnoredRecords[rec.Tag]:
+			report.Warnings = append(report.Warnings, domain.ImportWarning{
+				XRef: rec.XRef, Line: rec.Line, Message: fmt.Sprintf("unsupported record %s skipped", rec.Tag),
+			})
+		}
+	}
+
+	err = a.repository.Transaction(ctx, func(tx Repository) error {
+		for _, i := range indis {
+			id, err := tx.CreatePerson(ctx, i.person)
+			if err != nil {
+				return err
+			}
+
+			report.People[i.xref] = id
+		}
+
+		lineages := make([]gedcomLineage, 0)
+
+		for _, fam := range fams {
+			ls, err := importGEDCOMFamily(ctx, tx, fam, pedigrees, report)
+			if err != nil {
+				return err
+			}
+
+			lineages = append(lineages, ls...)
+		}
+
+		return a.importGEDCOMLineages(ctx, tx, lineages, report)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// gedcomPerson maps an INDI record to a person. The first NAME is the
+// person's name, while one of TYPE maiden gives their maiden name.
+func gedcomPerson(rec *gedcom.Record, report *domain.ImportReport) domain.Person {
+	var p domain.Person
+
+	for _, n := range rec.All("NAME") {
+		given, surname := gedcomNameParts(n)
+
+		if strings.EqualFold(n.ValueOf("TYPE"), "maiden") {
+			p.MaidenName = surname
+
+			continue
+		}
+
+		if p.Name != "" {
+			continue
+		}
+
+		p.Name = strings.Join(strings.Fields(strings.ReplaceAll(n.Value, "/", " ")), " ")
+		if p.Name == "" {
+			p.Name = strings.TrimSpace(given + " " + surname)
+		}
+
+		p.GivenName, p.Surname = given, surname
+	}
+
+	if p.Name == "" {
+		p.Name = "Unknown"
+
+		report.Warnings = append(report.Warnings, domain.ImportWarning{
+			XRef: rec.XRef, Line: rec.Line, Message: "individual has no name",
+		})
+	}
+
+	p.Sex = domain.SexUnknown
+
+	if sex := rec.First("SEX"); sex != nil {
+		if s, ok := gedcomSexes[strings.ToUpper(sex.Value)]; ok {
+			p.Sex = s
+		} else {
+			report.Warnings = append(report.Warnings, domain.ImportWarning{
+				XRef: rec.XRef, Line: sex.Line, Message: fmt.Sprintf("unknown sex %q", sex.Value),
+			})
+		}
+	}
+
+	p.BirthDate, p.BirthPlace = gedcomEvent(rec, "BIRT", report)
+	p.DeathDate, p.DeathPlace = gedcomEvent(rec, "DEAT", report)
+	p.Phonetic = phoneticKey(p)
+
+	return p
+}
+
+// gedcomNameParts returns the given name and surname of a NAME record,
+// from its GIVN and SURN tags or else from the slashes around the surname.
+func gedcomNameParts(n *gedcom.Record) (string, string) {
+	given, surname := n.ValueOf("GIVN"), n.ValueOf("SURN")
+
+	if parts := strings.SplitN(n.Value, "/", 3); len(parts) == 3 {
+		if given == "" {
+			given = strings.Join(strings.Fields(parts[0]+" "+parts[2]), " ")
+		}
+
+		if surname == "" {
+			surname = strings.TrimSpace(parts[1])
+		}
+	} else if given == "" {
+		given = strings.TrimSpace(n.Value)
+	}
+
+	return given, surname
+}
+
+// gedcomEvent returns the date and place of an event such as BIRT,
+// with a warning when its date cannot be read.
+func gedcomEvent(rec *gedcom.Record, tag string, report *domain.ImportReport) (*domain.PartialDate, string) {
+	ev := rec.First(tag)
+	if ev == nil {
+		return nil, ""
+	}
+
+	var date *domain.PartialDate
+
+	if d := ev.First("DATE"); d != nil {
+		v, err := domain.ParsePartialDate(d.Value)
+		if err != nil {
+			report.Warnings = append(report.Warnings, domain.ImportWarning{
+				XRef: rec.XRef, Line: d.Line, Message: err.Error(),
+			})
+		} else {
+			date = &v
+		}
+	}
+
+	return date, ev.ValueOf("PLAC")
+}
+
+// importGEDCOMFamily creates the partnership of a FAM record and returns a
+// relationship between each parent and each child, typed after the pedigree
+// the child's INDI record gives for the family, if any.
+func importGEDCOMFamily(
+	ctx context.Context, tx Repository, fam *gedcom.Record,
+	pedigrees map[[2]string]domain.RelationshipType, report *domain.ImportReport,
+) ([]gedcomLineage, error) {
+	parents := gedcomFamilyMembers(fam, append(fam.All("HUSB"), fam.All("WIFE")...), report)
+	children := gedcomFamilyMembers(fam, fam.All("CHIL"), report)
+
+	partnered := false
+
+	if dp, ok := gedcomPartnership(fam, parents, report); ok {
+		if _, err := tx.CreatePartnership(ctx, dp); err != nil {
+			return nil, err
+		}
+
+		partnered = true
+		report.Partnerships++
+	}
+
+	if len(parents) == 0 || len(children) == 0 {
+		if !partnered {
+			report.Warnings = append(report.Warnings, domain.ImportWarning{
+				XRef: fam.XRef, Line: fam.Line, Message: "family without both parents and children skipped",
+			})
+		}
+
+		return nil, nil
+	}
+
+	ls := make([]gedcomLineage, 0, len(parents)*len(children))
+
+	for _, p := range parents {
+		for _, c := range children {
+			t, ok := pedigrees[[2]string{c.Value, fam.XRef}]
+			if !ok {
+				t = domain.RelationshipBiological
+			}
+
+			ls = append(ls, gedcomLineage{fam, p, c, domain.Relationship{
+				ParentID: report.People[p.Value], ChildID: report.People[c.Value], Type: t,
+			}})
+		}
+	}
+
+	return ls, nil
+}
+
+// importGEDCOMLineages creates the relationships read from the FAM records,
+// following the rules relationships created one by one do, the consanguinity
+// policy included. As every imported person is new, their ancestry is held in
+// the file itself and is checked in memory rather than with a query per
+// relationship. Relationships breaking a rule, and offspring of parents too
+// closely related, are reported as warnings.
+func (a *Application) importGEDCOMLineages(
+	ctx context.Context, tx Repository, ls []gedcomLineage, report *domain.ImportReport,
+) error {
+	policy, maxDegree := a.config.Consanguinity.Policy, a.config.Consanguinity.MaxDegree
+
+	ls = validGEDCOMLineages(ls, report)
+
+	parents := make(map[string][]string)
+
+	for _, l := range ls {
+		if l.relationship.Type == domain.RelationshipBiological {
+			parents[l.relationship.ChildID] = append(parents[l.relationship.ChildID], l.relationship.ParentID)
+		}
+	}
+
+	created := make(map[string][]*gedcom.Record)
+
+	for _, l := range ls {
+		dr := l.relationship
+
+		if dr.Type == domain.RelationshipBiological && policy != ConsanguinityOff {
+			if coParent, degree, ok := gedcomConsanguinity(parents, dr.ParentID, created[dr.ChildID], report, maxDegree); ok {
+				if policy == ConsanguinityReject {
+					report.Warnings = append(report.Warnings, domain.ImportWarning{
+						XRef: l.fam.XRef, Line: l.child.Line,
+						Message: fmt.Sprintf("%s: %s as child of %s", ErrIncestuousOffspring, l.child.Value, l.parent.Value),
+					})
+
+					continue
+				}
+
+				report.Warnings = append(report.Warnings, domain.ImportWarning{
+					XRef: l.fam.XRef, Line: l.child.Line,
+					Message: fmt.Sprintf("parents %s and %s of %s are related in degree %d",
+						l.parent.Value, coParent.Value, l.child.Value, degree),
+				})
+			}
+		}
+
+		if _, err := tx.CreateRelationship(ctx, dr); err != nil {
+			return err
+		}
+
+		if dr.Type == domain.RelationshipBiological {
+			created[dr.ChildID] = append(created[dr.ChildID], l.parent)
+		}
+
+		report.Relationships++
+	}
+
+	return nil
+}
+
+// validGEDCOMLineages returns the lineages that break none of the rules
+// relationships follow, in the order given, each checked against those
+// before it: nobody is their own parent or ancestor, a parent and child are
+// related once and no child has more than two biological parents. The others
+// are reported as warnings.
+func validGEDCOMLineages(ls []gedcomLineage, report *domain.ImportReport) []gedcomLineage {
+	valid := make([]gedcomLineage, 0, len(ls))
+	parents := make(map[string][]string)
+	biological := make(map[string]int)
+
+	for _, l := range ls {
+		dr := l.relationship
+
+		var err error
+
+		switch {
+		case dr.ParentID == dr.ChildID:
+			err = ErrSelfParenting
+		case contains(parents[dr.ChildID], dr.ParentID):
+			err = ErrDuplicateRelationship
+		case dr.Type == domain.RelationshipBiological && biological[dr.ChildID] >= 2:
+			err = ErrTooManyParents
+		case gedcomAncestor(parents, dr.ParentID, dr.ChildID):
+			err = ErrRelationshipCycle
+		}
+
+		if err != nil {
+			report.Warnings = append(report.Warnings, domain.ImportWarning{
+				XRef: l.fam.XRef, Line: l.child.Line,
+				Message: fmt.Sprintf("%s: %s as child of %s", err, l.child.Value, l.parent.Value),
+			})
+
+			continue
+		}
+
+		parents[dr.ChildID] = append(parents[dr.ChildID], dr.ParentID)
+
+		if dr.Type == domain.RelationshipBiological {
+			biological[dr.ChildID]++
+		}
+
+		valid = append(valid, l)
+	}
+
+	return valid
+}
+
+// gedcomAncestor reports whether ancestor is found going up the parents
+// of a person, of any kind.
+func gedcomAncestor(parents map[string][]string, id, ancestor string) bool {
+	seen := map[string]bool{id: true}
+	queue := []string{id}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+
+		for _, p := range parents[cur] {
+			if p == ancestor {
+				return true
+			}
+
+			if !seen[p] {
+				seen[p] = true
+				queue = append(queue, p)
+			}
+		}
+	}
+
+	return false
+}
+
+// gedcomConsanguinity returns the first of the given co-parents related to a
+// parent within maxDegree, along with their degree of kinship.
+func gedcomConsanguinity(
+	parents map[string][]string, parent string, coParents []*gedcom.Record, report *domain.ImportReport, maxDegree int,
+) (*gedcom.Record, int, bool) {
+	if len(coParents) == 0 {
+		return nil, 0, false
+	}
+
+	a1 := ancestryDepths(parents, parent, maxDegree)
+
+	for _, cp := range coParents {
+		shared, d1, d2 := closestCommonAncestors(a1, ancestryDepths(parents, report.People[cp.Value], maxDegree))
+		if len(shared) > 0 && d1+d2 <= maxDegree {
+			return cp, d1 + d2, true
+		}
+	}
+
+	return nil, 0, false
+}
+
+// gedcomPartnership maps the MARR and DIV events of a FAM record with two
+// partners to a partnership. A MARR with a TYPE other than marriage, such as
+// civil union, gives the type of the partnership.
+func gedcomPartnership(
+	fam *gedcom.Record, partners []*gedcom.Record, report *domain.ImportReport,
+) (domain.Partnership, bool) {
+	marr, div := fam.First("MARR"), fam.First("DIV")
+	if len(partners) != 2 || (marr == nil && div == nil) {
+		return domain.Partnership{}, false
+	}
+
+	dp := domain.Partnership{
+		Person1ID: report.People[partners[0].Value],
+		Person2ID: report.People[partners[1].Value],
+		Type:      domain.PartnershipMarriage,
+	}
+
+	if marr != nil {
+		switch strings.ToLower(marr.ValueOf("TYPE")) {
+		case "civil union", "civil-union":
+			dp.Type = domain.PartnershipCivilUnion
+		case "partnership", "partners", "domestic partnership":
+			dp.Type = domain.PartnershipPartnership
+		}
+
+		dp.StartDate, _ = gedcomEvent(fam, "MARR", report)
+	}
+
+	if div != nil {
+		dp.EndReason = domain.EndReasonDivorce
+		dp.EndDate, _ = gedcomEvent(fam, "DIV", report)
+	}
+
+	return dp, true
+}
+
+// gedcomFamilyMembers returns the records pointing to individuals that were
+// imported, with a warning for each one pointing elsewhere.
+func gedcomFamilyMembers(fam *gedcom.Record, rs []*gedcom.Record, report *domain.ImportReport) []*gedcom.Record {
+	known := make([]*gedcom.Record, 0, len(rs))
+
+	for _, r := range rs {
+		if _, ok := report.People[r.Value]; !ok {
+			report.Warnings = append(report.Warnings, domain.ImportWarning{
+				XRef: fam.XRef, Line: r.Line, Message: fmt.Sprintf("unknown individual %s", r.Value),
+			})
+
+			continue
+		}
+
+		known = append(known, r)
+	}
+
+	return known
+}
@@ -0,0 +1,580 @@
+package app
+
+import (
+	"context"
+	"sort"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+)
+
+// familyGraph is a lazily loaded view of the relationships table.
+// Edges are fetched one frontier at a time, so a search only reads
+// the part of the graph it actually visits.
+type familyGraph struct {
+	repository   Repository
+	loaded       map[string]bool
+	seen         map[string]bool
+	edges        map[string][]*domain.Relationship
+	partnerships map[string][]*domain.Partnership
+}
+
+// newFamilyGraph returns an empty graph backed by the given repository.
+func newFamilyGraph(repository Repository) *familyGraph {
+	return &familyGraph{
+		repository:   repository,
+		loaded:       make(map[string]bool),
+		seen:         make(map[string]bool),
+		edges:        make(map[string][]*domain.Relationship),
+		partnerships: make(map[string][]*domain.Partnership),
+	}
+}
+
+// load fetches the edges of the given people that were not loaded yet.
+func (g *familyGraph) load(ctx context.Context, ids []string) error {
+	missing := make([]string, 0, len(ids))
+
+	for _, id := range ids {
+		if !g.loaded[id] {
+			g.loaded[id] = true
+
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	rs, err := g.repository.ListRelationshipsByPersonIDs(ctx, missing)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range rs {
+		if g.seen[r.ID] {
+			continue
+		}
+
+		g.seen[r.ID] = true
+		g.edges[r.ParentID] = append(g.edges[r.ParentID], r)
+		g.edges[r.ChildID] = append(g.edges[r.ChildID], r)
+	}
+
+	return nil
+}
+
+// neighbours returns the parents and children of a loaded person.
+func (g *familyGraph) neighbours(id string) []string {
+	ns := make([]string, 0, len(g.edges[id]))
+
+	for _, r := range g.edges[id] {
+		if r.ParentID == id {
+			ns = append(ns, r.ChildID)
+		} else {
+			ns = append(ns, r.ParentID)
+		}
+	}
+
+	return ns
+}
+
+// parentsOf loads a person and returns their parents.
+func (g *familyGraph) parentsOf(ctx context.Context, id string) ([]string, error) {
+	if err := g.load(ctx, []string{id}); err != nil {
+		return nil, err
+	}
+
+	return g.parents(id), nil
+}
+
+// childrenOf loads a person and returns their children.
+func (g *familyGraph) childrenOf(ctx context.Context, id string) ([]string, error) {
+	if err := g.load(ctx, []string{id}); err != nil {
+		return nil, err
+	}
+
+	return g.children(id), nil
+}
+
+// parents returns the parents of a loaded person.
+func (g *familyGraph) parents(id string) []string {
+	ps := make([]string, 0, len(g.edges[id]))
+
+	for _, r := range g.edges[id] {
+		if r.ChildID == id {
+			ps = append(ps, r.ParentID)
+		}
+	}
+
+	return ps
+}
+
+// kinParents returns the parents a loaded person descends from,
+// by birth or adoption, leaving out step and foster parents.
+func (g *familyGraph) kinParents(id string) []string {
+	ps := make([]string, 0, len(g.edges[id]))
+
+	for _, r := range g.edges[id] {
+		if r.ChildID == id && r.Type.Kin() {
+			ps = append(ps, r.ParentID)
+		}
+	}
+
+	return ps
+}
+
+// relationshipType returns the type of the relationship between a parent
+// and a child, both loaded, or an empty type when there is none.
+func (g *familyGraph) relationshipType(parent, child string) domain.RelationshipType {
+	for _, r := range g.edges[child] {
+		if r.ParentID == parent && r.ChildID == child {
+			if r.Type == "" {
+				return domain.RelationshipBiological
+			}
+
+			return r.Type
+		}
+	}
+
+	return ""
+}
+
+// kinChildren returns the children descending from a loaded person,
+// by birth or adoption, leaving out step and foster children.
+func (g *familyGraph) kinChildren(id string) []string {
+	cs := make([]string, 0, len(g.edges[id]))
+
+	for _, r := range g.edges[id] {
+		if r.ParentID == id && r.Type.Kin() {
+			cs = append(cs, r.ChildID)
+		}
+	}
+
+	return cs
+}
+
+// children returns the children of a loaded person.
+func (g *familyGraph) children(id string) []string {
+	cs := make([]string, 0, len(g.edges[id]))
+
+	for _, r := range g.edges[id] {
+		if r.ParentID == id {
+			cs = append(cs, r.ChildID)
+		}
+	}
+
+	return cs
+}
+
+// siblings returns the people sharing at least one parent with a person.
+// Full siblings share every parent the person descends from, half siblings
+// only some, and step siblings share none of them but are linked through a
+// step or foster parent.
+func (g *familyGraph) siblings(ctx context.Context, id string) ([]string, []string, []string, error) {
+	if err := g.load(ctx, []string{id}); err != nil {
+		return nil, nil, nil, err
+	}
+
+	ps := g.parents(id)
+	if err := g.load(ctx, ps); err != nil {
+		return nil, nil, nil, err
+	}
+
+	candidates := make([]string, 0)
+	found := map[string]bool{id: true}
+
+	for _, p := range ps {
+		for _, c := range g.children(p) {
+			if !found[c] {
+				found[c] = true
+
+				candidates = append(candidates, c)
+			}
+		}
+	}
+
+	if err := g.load(ctx, candidates); err != nil {
+		return nil, nil, nil, err
+	}
+
+	kin := g.kinParents(id)
+	full, half, step := make([]string, 0), make([]string, 0), make([]string, 0)
+
+	for _, c := range candidates {
+		cs := g.kinParents(c)
+
+		switch {
+		case !sharesAny(kin, cs):
+			step = append(step, c)
+		case sameMembers(kin, cs):
+			full = append(full, c)
+		default:
+			half = append(half, c)
+		}
+	}
+
+	sort.Strings(full)
+	sort.Strings(half)
+	sort.Strings(step)
+
+	return full, half, step, nil
+}
+
+// partnershipsOf returns the partnerships of a person, current ones first,
+// then the most recently started.
+func (g *familyGraph) partnershipsOf(ctx context.Context, id string) ([]*domain.Partnership, error) {
+	if ps, ok := g.partnerships[id]; ok {
+		return ps, nil
+	}
+
+	ps, err := g.repository.ListPartnershipsByPersonIDs(ctx, []string{id})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(ps, func(i, j int) bool {
+		if ps[i].Current() != ps[j].Current() {
+			return ps[i].Current()
+		}
+
+		return startYear(ps[i]) > startYear(ps[j])
+	})
+
+	g.partnerships[id] = ps
+
+	return ps, nil
+}
+
+// spouses returns the current partners of a person,
+// followed by the people they share children with.
+func (g *familyGraph) spouses(ctx context.Context, id string) ([]string, error) {
+	ps, err := g.partnershipsOf(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	cps, err := g.coParents(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(ps)+len(cps))
+
+	for _, p := range ps {
+		if p.Current() && !contains(ids, p.Partner(id)) {
+			ids = append(ids, p.Partner(id))
+		}
+	}
+
+	for _, cp := range cps {
+		if !contains(ids, cp) {
+			ids = append(ids, cp)
+		}
+	}
+
+	return ids, nil
+}
+
+// startYear returns the year a partnership started, or zero if unknown.
+func startYear(p *domain.Partnership) int {
+	if p.StartDate == nil {
+		return 0
+	}
+
+	return p.StartDate.Year
+}
+
+// coParents returns the people who share a child with a person, ordered
+// by the number of children they share, most first.
+func (g *familyGraph) coParents(ctx context.Context, id string) ([]string, error) {
+	if err := g.load(ctx, []string{id}); err != nil {
+		return nil, err
+	}
+
+	cs := g.children(id)
+	if err := g.load(ctx, cs); err != nil {
+		return nil, err
+	}
+
+	shared := make(map[string]int)
+
+	for _, c := range cs {
+		for _, p := range g.parents(c) {
+			if p != id {
+				shared[p]++
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(shared))
+	for p := range shared {
+		ids = append(ids, p)
+	}
+
+	sort.Slice(ids, func(i, j int) bool {
+		if shared[ids[i]] != shared[ids[j]] {
+			return shared[ids[i]] > shared[ids[j]]
+		}
+
+		return ids[i] < ids[j]
+	})
+
+	return ids, nil
+}
+
+// ancestors walks the kin parent edges upwards from a person and returns every
+// ancestor found with its distance in generations. The person itself is
+// included at distance zero. The second map records, for each ancestor,
+// the child through which it was first reached.
+func (g *familyGraph) ancestors(ctx context.Context, id string) (map[string]int, map[string]string, error) {
+	depth := map[string]int{id: 0}
+	via := make(map[string]string)
+	frontier := []string{id}
+
+	for d := 1; len(frontier) > 0; d++ {
+		if err := g.load(ctx, frontier); err != nil {
+			return nil, nil, err
+		}
+
+		next := make([]string, 0)
+
+		for _, c := range frontier {
+			for _, p := range g.kinParents(c) {
+				if _, ok := depth[p]; ok {
+					continue
+				}
+
+				depth[p] = d
+				via[p] = c
+				next = append(next, p)
+			}
+		}
+
+		frontier = next
+	}
+
+	return depth, via, nil
+}
+
+// descendsFrom reports whether a person descends from another through
+// parent edges of any type, ignoring the relationship with the skip ID.
+func (g *familyGraph) descendsFrom(ctx context.Context, id, ancestor, skip string) (bool, error) {
+	visited := map[string]bool{id: true}
+	frontier := []string{id}
+
+	for len(frontier) > 0 {
+		if err := g.load(ctx, frontier); err != nil {
+			return false, err
+		}
+
+		next := make([]string, 0)
+
+		for _, c := range frontier {
+			for _, r := range g.edges[c] {
+				if r.ChildID != c || r.ID == skip || visited[r.ParentID] {
+					continue
+				}
+
+				if r.ParentID == ancestor {
+					return true, nil
+				}
+
+				visited[r.ParentID] = true
+				next = append(next, r.ParentID)
+			}
+		}
+
+		frontier = next
+	}
+
+	return false, nil
+}
+
+// descendants returns the people descending from a person by birth or
+// adoption, generation by generation, loading them on the way. Step and
+// foster children are left out, along with those descending from them only.
+func (g *familyGraph) descendants(ctx context.Context, id string) ([]string, error) {
+	visited := map[string]bool{id: true}
+	frontier := []string{id}
+	ds := make([]string, 0)
+
+	for len(frontier) > 0 {
+		if err := g.load(ctx, frontier); err != nil {
+			return nil, err
+		}
+
+		next := make([]string, 0)
+
+		for _, p := range frontier {
+			for _, c := range g.kinChildren(p) {
+				if !visited[c] {
+					visited[c] = true
+					next = append(next, c)
+				}
+			}
+		}
+
+		ds = append(ds, next...)
+		frontier = next
+	}
+
+	return ds, nil
+}
+
+// lineage returns the IDs going from a person up to one of their
+// ancestors, both included, following the links recorded by ancestors.
+func lineage(via map[string]string, from, ancestor string) []string {
+	ids := []string{ancestor}
+	for id := ancestor; id != from; id = via[id] {
+		ids = append([]string{via[id]}, ids...)
+	}
+
+	return ids
+}
+
+// ancestryDepths walks an in-memory map of parents upwards from a person,
+// up to maxDepth generations, and returns every ancestor found with its
+// distance in generations, the person itself included at distance zero.
+func ancestryDepths(parents map[string][]string, id string, maxDepth int) map[string]int {
+	depth := map[string]int{id: 0}
+	frontier := []string{id}
+
+	for d := 1; d <= maxDepth && len(frontier) > 0; d++ {
+		next := make([]string, 0)
+
+		for _, c := range frontier {
+			for _, p := range parents[c] {
+				if _, ok := depth[p]; ok {
+					continue
+				}
+
+				depth[p] = d
+				next = append(next, p)
+			}
+		}
+
+		frontier = next
+	}
+
+	return depth
+}
+
+// closestCommonAncestors returns the ancestors shared by two people that are
+// the fewest generations away from both, along with their distance to each.
+func closestCommonAncestors(a1, a2 map[string]int) ([]string, int, int) {
+	var (
+		shared []string
+		d1, d2 int
+	)
+
+	for id, x := range a1 {
+		y, ok := a2[id]
+		if !ok {
+			continue
+		}
+
+		switch {
+		case shared == nil || x+y < d1+d2 || (x+y == d1+d2 && x < d1):
+			shared, d1, d2 = []string{id}, x, y
+		case x+y == d1+d2 && x == d1:
+			shared = append(shared, id)
+		}
+	}
+
+	sort.Strings(shared)
+
+	return shared, d1, d2
+}
+
+// shortestPath runs a breadth-first search from one person to another,
+// following parent and child edges in both directions. It returns the IDs
+// along the path, both ends included, or nil when they are not connected.
+func (g *familyGraph) shortestPath(ctx context.Context, from, to string) ([]string, error) {
+	prev := map[string]string{from: ""}
+	frontier := []string{from}
+
+	for len(frontier) > 0 {
+		if _, ok := prev[to]; ok {
+			break
+		}
+
+		if err := g.load(ctx, frontier); err != nil {
+			return nil, err
+		}
+
+		next := make([]string, 0)
+
+		for _, id := range frontier {
+			for _, n := range g.neighbours(id) {
+				if _, ok := prev[n]; ok {
+					continue
+				}
+
+				prev[n] = id
+				next = append(next, n)
+			}
+		}
+
+		frontier = next
+	}
+
+	if _, ok := prev[to]; !ok {
+		return nil, nil
+	}
+
+	path := []string{to}
+	for id := prev[to]; id != ""; id = prev[id] {
+		path = append([]string{id}, path...)
+	}
+
+	return path, nil
+}
+
+// sameMembers reports whether two ID lists hold the same set of IDs.
+func sameMembers(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	set := make(map[string]bool, len(a))
+	for _, id := range a {
+		set[id] = true
+	}
+
+	for _, id := range b {
+		if !set[id] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// sharesAny reports whether two ID lists have an ID in common.
+func sharesAny(a, b []string) bool {
+	for _, id := range a {
+		if contains(b, id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// orderPeople returns people in the order of the given IDs.
+func orderPeople(people []*domain.Person, ids []string) []*domain.Person {
+	byID := make(map[string]*domain.Person, len(people))
+	for _, p := range people {
+		byID[p.ID] = p
+	}
+
+	ordered := make([]*domain.Person, 0, len(ids))
+
+	for _, id := range ids {
+		if p, ok := byID[id]; ok {
+			ordered = append(ordered, p)
+		}
+	}
+
+	return ordered
+}
@@ -0,0 +1,380 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+)
+
+// kinship works out how one person is related to another. It returns the
+// English term describing the first person from the second one's point of
+// view, and the IDs of the people justifying it. A nil path means they are
+// not connected at all.
+func (g *familyGraph) kinship(ctx context.Context, from, to string) (string, []string, error) {
+	if from == to {
+		return "self", []string{from}, nil
+	}
+
+	term, path, err := g.bloodKinship(ctx, from, to)
+	if err != nil || path != nil {
+		return term, path, err
+	}
+
+	term, path, err = g.affinity(ctx, from, to)
+	if err != nil || path != nil {
+		return term, path, err
+	}
+
+	path, err = g.shortestPath(ctx, from, to)
+	if err != nil || path == nil {
+		return "", nil, err
+	}
+
+	return "relative", path, nil
+}
+
+// bloodKinship names the relationship between two people descending
+// from a common ancestor, or returns a nil path if there is none.
+func (g *familyGraph) bloodKinship(ctx context.Context, from, to string) (string, []string, error) {
+	a1, via1, err := g.ancestors(ctx, from)
+	if err != nil {
+		return "", nil, err
+	}
+
+	a2, via2, err := g.ancestors(ctx, to)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ids, d1, d2 := closestCommonAncestors(a1, a2)
+	if len(ids) == 0 {
+		return "", nil, nil
+	}
+
+	up := lineage(via1, from, ids[0])
+	down := lineage(via2, to, ids[0])
+
+	path := up
+	for i := len(down) - 2; i >= 0; i-- {
+		path = append(path, down[i])
+	}
+
+	// Collateral relatives are half relatives when the two lines branch
+	// off from siblings who do not share every parent.
+	half := false
+	if d1 > 0 && d2 > 0 {
+		half = !sameMembers(g.kinParents(up[len(up)-2]), g.kinParents(down[len(down)-2]))
+	}
+
+	term := bloodTerm(d1, d2, half)
+
+	// Relatives whose lines go through an adoption are adoptive relatives.
+	for _, line := range [][]string{up, down} {
+		for i := 1; i < len(line); i++ {
+			if g.relationshipType(line[i], line[i-1]) == domain.RelationshipAdoptive {
+				return "adoptive " + term, path, nil
+			}
+		}
+	}
+
+	return term, path, nil
+}
+
+// affinity names relationships through a partner, such as step and
+// in-law relatives, or returns a nil path if there is none. Recorded
+// partnerships come first, then people sharing children are taken as spouses.
+func (g *familyGraph) affinity(ctx context.Context, from, to string) (string, []string, error) {
+	ps, err := g.partnershipsOf(ctx, from)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, p := range ps {
+		if p.Partner(from) == to {
+			return partnerTerm(p), []string{from, to}, nil
+		}
+	}
+
+	spouses, err := g.spouses(ctx, from)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if contains(spouses, to) {
+		return "spouse", []string{from, to}, nil
+	}
+
+	switch t := g.relationshipType(from, to); t {
+	case domain.RelationshipStep, domain.RelationshipFoster:
+		return affinityTerm(t, "parent"), []string{from, to}, nil
+	}
+
+	switch t := g.relationshipType(to, from); t {
+	case domain.RelationshipStep, domain.RelationshipFoster:
+		return affinityTerm(t, "child"), []string{from, to}, nil
+	}
+
+	_, _, step, err := g.siblings(ctx, from)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if contains(step, to) {
+		for _, p := range g.parents(from) {
+			if contains(g.parents(to), p) {
+				t := g.relationshipType(p, from)
+				if t.Kin() {
+					t = g.relationshipType(p, to)
+				}
+
+				return affinityTerm(t, "sibling"), []string{from, p, to}, nil
+			}
+		}
+	}
+
+	fromParents, err := g.parentsOf(ctx, from)
+	if err != nil {
+		return "", nil, err
+	}
+
+	toParents, err := g.parentsOf(ctx, to)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, p := range toParents {
+		ps, err := g.spouses(ctx, p)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if contains(ps, from) && !contains(toParents, from) {
+			return "step-parent", []string{from, p, to}, nil
+		}
+	}
+
+	for _, p := range fromParents {
+		ps, err := g.spouses(ctx, p)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if contains(ps, to) && !contains(fromParents, to) {
+			return "step-child", []string{from, p, to}, nil
+		}
+
+		for _, s := range ps {
+			if contains(fromParents, s) {
+				continue
+			}
+
+			cs, err := g.childrenOf(ctx, s)
+			if err != nil {
+				return "", nil, err
+			}
+
+			if contains(cs, to) {
+				return "step-sibling", []string{from, p, s, to}, nil
+			}
+		}
+	}
+
+	for _, s := range spouses {
+		ps, err := g.parentsOf(ctx, s)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if contains(ps, to) {
+			return "child-in-law", []string{from, s, to}, nil
+		}
+
+		full, half, _, err := g.siblings(ctx, s)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if contains(full, to) || contains(half, to) {
+			return "sibling-in-law", []string{from, s, to}, nil
+		}
+	}
+
+	toSpouses, err := g.spouses(ctx, to)
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, s := range toSpouses {
+		ps, err := g.parentsOf(ctx, s)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if contains(ps, from) {
+			return "parent-in-law", []string{from, s, to}, nil
+		}
+
+		full, half, _, err := g.siblings(ctx, s)
+		if err != nil {
+			return "", nil, err
+		}
+
+		if contains(full, from) || contains(half, from) {
+			return "sibling-in-law", []string{from, s, to}, nil
+		}
+	}
+
+	return "", nil, nil
+}
+
+// partnerTerm names a partner, as in spouse or former partner. Partners
+// widowed by death stay spouses rather than becoming former ones.
+func partnerTerm(p *domain.Partnership) string {
+	term := "spouse"
+	if p.Type == domain.PartnershipPartnership {
+		term = "partner"
+	}
+
+	if !p.Current() && p.EndReason != domain.EndReasonDeath {
+		return "former " + term
+	}
+
+	return term
+}
+
+// affinityTerm names a relative through a step or foster relationship,
+// as in step-parent or foster child.
+func affinityTerm(t domain.RelationshipType, base string) string {
+	if t == domain.RelationshipFoster {
+		return "foster " + base
+	}
+
+	return "step-" + base
+}
+
+// bloodTerm names a person who is d1 generations below a common ancestor
+// from the point of view of someone d2 generations below it.
+func bloodTerm(d1, d2 int, half bool) string {
+	prefix := ""
+	if half {
+		prefix = "half-"
+	}
+
+	switch {
+	case d1 == 0:
+		return lineal(d2, "parent")
+	case d2 == 0:
+		return lineal(d1, "child")
+	case d1 == 1 && d2 == 1:
+		return prefix + "sibling"
+	case d1 == 1:
+		return prefix + collateral(d2-1, "aunt/uncle")
+	case d2 == 1:
+		return prefix + collateral(d1-1, "niece/nephew")
+	}
+
+	degree, removed := d1-1, d2-d1
+	if d2 < d1 {
+		degree, removed = d2-1, d1-d2
+	}
+
+	if half {
+		return "half " + cousin(degree, removed)
+	}
+
+	return cousin(degree, removed)
+}
+
+// lineal names a direct ancestor or descendant n generations away,
+// as in parent, grandparent and great-grandparent.
+func lineal(n int, base string) string {
+	if n == 1 {
+		return base
+	}
+
+	return strings.Repeat("great-", n-2) + "grand" + base
+}
+
+// collateral names the sibling of an ancestor, or the descendant of a
+// sibling, n generations away, as in aunt, grand-aunt and great-grand-aunt.
+func collateral(n int, base string) string {
+	if n == 1 {
+		return base
+	}
+
+	return strings.Repeat("great-", n-2) + "grand-" + base
+}
+
+// cousin names a cousin of the given degree and removal,
+// as in first cousin or second cousin once removed.
+func cousin(degree, removed int) string {
+	ordinals := []string{
+		"", "first", "second", "third", "fourth", "fifth",
+		"sixth", "seventh", "eighth", "ninth", "tenth",
+	}
+
+	term := fmt.Sprintf("%d%s cousin", degree, ordinalSuffix(degree))
+	if degree < len(ordinals) {
+		term = ordinals[degree] + " cousin"
+	}
+
+	switch removed {
+	case 0:
+		return term
+	case 1:
+		return term + " once removed"
+	case 2:
+		return term + " twice removed"
+	default:
+		return fmt.Sprintf("%s %d times removed", term, removed)
+	}
+}
+
+// ordinalSuffix returns the English suffix of an ordinal number.
+func ordinalSuffix(n int) string {
+	if n%100 >= 11 && n%100 <= 13 {
+		return "th"
+	}
+
+	switch n % 10 {
+	case 1:
+		return "st"
+	case 2:
+		return "nd"
+	case 3:
+		return "rd"
+	default:
+		return "th"
+	}
+}
+
+// genderedTerm picks the form of a term matching the sex of the person
+// it describes, as in aunt or uncle, keeping both when the sex is unknown.
+func genderedTerm(term string, sex domain.Sex) string {
+	for _, pair := range [][2]string{{"aunt", "uncle"}, {"niece", "nephew"}} {
+		both := pair[0] + "/" + pair[1]
+
+		switch sex {
+		case domain.SexFemale:
+			term = strings.ReplaceAll(term, both, pair[0])
+		case domain.SexMale:
+			term = strings.ReplaceAll(term, both, pair[1])
+		case domain.SexOther, domain.SexUnknown:
+		}
+	}
+
+	return term
+}
+
+// contains reports whether ids holds id.
+func contains(ids []string, id string) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,207 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// Cousins returns the cousin degree and removal between two people,
+// along with the closest ancestors they share.
+func (a *Application) Cousins(ctx context.Context, id1, id2 string) (*domain.Cousins, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "Cousins")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	p1, err := a.repository.GetPersonByID(ctx, id1)
+	if err != nil {
+		return nil, err
+	}
+
+	p2, err := a.repository.GetPersonByID(ctx, id2)
+	if err != nil {
+		return nil, err
+	}
+
+	g := newFamilyGraph(a.repository)
+
+	a1, _, err := g.ancestors(ctx, p1.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	a2, _, err := g.ancestors(ctx, p2.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, d1, d2 := closestCommonAncestors(a1, a2)
+	if len(ids) == 0 {
+		return nil, ErrNoSharedAncestor
+	}
+
+	// A shared parent makes them siblings, aunt and nephew and so on,
+	// and a distance of zero means one descends from the other.
+	if d1 < 2 || d2 < 2 {
+		return nil, ErrNotCousins
+	}
+
+	people, err := a.repository.ListPeopleByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	degree, removed := d1-1, d2-d1
+	if d2 < d1 {
+		degree, removed = d2-1, d1-d2
+	}
+
+	shared := make([]domain.Person, 0, len(ids))
+	for _, p := range orderPeople(people, ids) {
+		shared = append(shared, *p)
+	}
+
+	return &domain.Cousins{
+		Person1: *p1,
+		Person2: *p2,
+		Shared:  shared,
+		Degree:  degree,
+		Removed: removed,
+	}, nil
+}
+
+// Kinship returns how the first person is related to the second.
+func (a *Application) Kinship(ctx context.Context, id1, id2 string) (*domain.Kinship, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "Kinship")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	p1, err := a.repository.GetPersonByID(ctx, id1)
+	if err != nil {
+		return nil, err
+	}
+
+	p2, err := a.repository.GetPersonByID(ctx, id2)
+	if err != nil {
+		return nil, err
+	}
+
+	term, ids, err := newFamilyGraph(a.repository).kinship(ctx, p1.ID, p2.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ids == nil {
+		return nil, ErrPeopleNotConnected
+	}
+
+	people, err := a.repository.ListPeopleByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.Kinship{
+		Person1: *p1,
+		Person2: *p2,
+		Term:    genderedTerm(term, p1.Sex),
+		Path:    orderPeople(people, ids),
+	}, nil
+}
+
+// populateKin fills the relatives of a person asked for: their parents and
+// children; their siblings, half siblings and step siblings; or their
+// partnerships and spouse. The spouse is their current partner or, lacking
+// any recorded partnership, the person they share the most children with.
+func (a *Application) populateKin(ctx context.Context, p *domain.Person, include map[domain.PersonInclude]bool) error {
+	g := newFamilyGraph(a.repository)
+
+	var parents, children, full, half, step, spouse []string
+
+	if include[domain.PersonIncludeParents] || include[domain.PersonIncludeChildren] {
+		var err error
+
+		if parents, err = g.parentsOf(ctx, p.ID); err != nil {
+			return err
+		}
+
+		if children, err = g.childrenOf(ctx, p.ID); err != nil {
+			return err
+		}
+
+		if !include[domain.PersonIncludeParents] {
+			parents = nil
+		}
+
+		if !include[domain.PersonIncludeChildren] {
+			children = nil
+		}
+	}
+
+	if include[domain.PersonIncludeSiblings] {
+		var err error
+
+		if full, half, step, err = g.siblings(ctx, p.ID); err != nil {
+			return err
+		}
+	}
+
+	if include[domain.PersonIncludeSpouse] {
+		ps, err := g.partnershipsOf(ctx, p.ID)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case len(ps) > 0 && ps[0].Current():
+			spouse = []string{ps[0].Partner(p.ID)}
+		case len(ps) == 0:
+			cps, err := g.coParents(ctx, p.ID)
+			if err != nil {
+				return err
+			}
+
+			if len(cps) > 0 {
+				spouse = cps[:1]
+			}
+		}
+
+		p.Partnerships = ps
+	}
+
+	ids := make([]string, 0, len(parents)+len(children)+len(full)+len(half)+len(step)+len(spouse))
+	for _, kin := range [][]string{parents, children, full, half, step, spouse} {
+		ids = append(ids, kin...)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	people, err := a.repository.ListPeopleByIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	p.Parents = orderPeople(people, parents)
+	p.Children = orderPeople(people, children)
+	p.Siblings = orderPeople(people, full)
+	p.HalfSiblings = orderPeople(people, half)
+	p.StepSiblings = orderPeople(people, step)
+
+	if s := orderPeople(people, spouse); len(s) > 0 {
+		p.Spouse = s[0]
+	}
+
+	return nil
+}
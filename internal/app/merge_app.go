@@ -0,0 +1,196 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// MaxDuplicateLimit is the largest number of duplicate candidates listed at once.
+const MaxDuplicateLimit = 100
+
+// defaultDuplicateLimit is the number of duplicate candidates listed when no limit is given.
+const defaultDuplicateLimit = 20
+
+// defaultDuplicateScore is the lowest score of the duplicate candidates listed when none is given.
+const defaultDuplicateScore = 0.5
+
+// ListDuplicateCandidates returns the pairs of people that may be the same
+// person, scored by how alike their names are and how many relatives they
+// share, the likeliest first.
+func (a *Application) ListDuplicateCandidates(ctx context.Context, q domain.DuplicateQuery) ([]*domain.DuplicateCandidate, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListDuplicateCandidates")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultDuplicateLimit
+	case q.Limit < 0 || q.Limit > MaxDuplicateLimit:
+		return nil, ErrInvalidLimit
+	}
+
+	switch {
+	case q.MinScore == 0:
+		q.MinScore = defaultDuplicateScore
+	case q.MinScore < 0:
+		return nil, ErrInvalidScore
+	}
+
+	if q.PersonID != "" {
+		if _, err := a.repository.GetPersonByID(ctx, q.PersonID); err != nil {
+			return nil, err
+		}
+	}
+
+	cs, err := a.repository.ListDuplicateCandidates(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return cs, nil
+}
+
+// MergePeople merges a duplicate into the person surviving them in a single
+// transaction. The survivor takes over the relationships and partnerships of
+// the duplicate, but for those they already have, and any detail of the
+// duplicate missing from their own profile; the duplicate is then deleted
+// and the merge recorded. A person cannot be merged with one of their
+// ancestors or descendants, nor end up with more than two biological parents.
+func (a *Application) MergePeople(ctx context.Context, survivorID, duplicateID string) (*domain.PersonMerge, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "MergePeople")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	if survivorID == duplicateID {
+		return nil, ErrInvalidMerge
+	}
+
+	var m *domain.PersonMerge
+
+	err := a.repository.Transaction(ctx, func(tx Repository) error {
+		survivor, err := tx.GetPersonByID(ctx, survivorID)
+		if err != nil {
+			return err
+		}
+
+		duplicate, err := tx.GetPersonByID(ctx, duplicateID)
+		if err != nil {
+			return err
+		}
+
+		g := newFamilyGraph(tx)
+
+		for _, p := range [][2]string{{survivorID, duplicateID}, {duplicateID, survivorID}} {
+			related, err := g.descendsFrom(ctx, p[0], p[1], "")
+			if err != nil {
+				return err
+			}
+
+			if related {
+				return ErrInvalidMerge
+			}
+		}
+
+		m = &domain.PersonMerge{SurvivorID: survivorID, DuplicateID: duplicateID, DuplicateName: duplicate.Name}
+		if err := tx.MergePerson(ctx, m); err != nil {
+			return err
+		}
+
+		parents, err := tx.ListRelationships(ctx, domain.RelationshipFilter{
+			ChildID: survivorID,
+			Type:    domain.RelationshipBiological,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(parents) > 2 {
+			return ErrTooManyParents
+		}
+
+		if fill, ok := mergeProfile(survivor, duplicate); ok {
+			return tx.UpdatePerson(ctx, fill)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// ListPersonMerges returns the merges a person took part in, as the survivor
+// or as the duplicate, or every merge when no person is given.
+func (a *Application) ListPersonMerges(ctx context.Context, personID string) ([]*domain.PersonMerge, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListPersonMerges")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	ms, err := a.repository.ListPersonMerges(ctx, personID)
+	if err != nil {
+		return nil, err
+	}
+
+	return ms, nil
+}
+
+// mergeProfile returns the update filling in the details missing from the
+// profile of a survivor with those of the duplicate, and whether there is any.
+func mergeProfile(survivor, duplicate *domain.Person) (domain.Person, bool) {
+	fill := domain.Person{ID: survivor.ID}
+	merged := *survivor
+	changed := false
+
+	for _, f := range []struct{ cur, dup, fill, merged *string }{
+		{&survivor.Name, &duplicate.Name, &fill.Name, &merged.Name},
+		{&survivor.GivenName, &duplicate.GivenName, &fill.GivenName, &merged.GivenName},
+		{&survivor.Surname, &duplicate.Surname, &fill.Surname, &merged.Surname},
+		{&survivor.MaidenName, &duplicate.MaidenName, &fill.MaidenName, &merged.MaidenName},
+		{&survivor.BirthPlace, &duplicate.BirthPlace, &fill.BirthPlace, &merged.BirthPlace},
+		{&survivor.DeathPlace, &duplicate.DeathPlace, &fill.DeathPlace, &merged.DeathPlace},
+	} {
+		if *f.cur == "" && *f.dup != "" {
+			*f.fill, *f.merged = *f.dup, *f.dup
+			changed = true
+		}
+	}
+
+	if (survivor.Sex == "" || survivor.Sex == domain.SexUnknown) && duplicate.Sex != "" && duplicate.Sex != domain.SexUnknown {
+		fill.Sex = duplicate.Sex
+		changed = true
+	}
+
+	if survivor.BirthDate == nil && duplicate.BirthDate != nil {
+		fill.BirthDate = duplicate.BirthDate
+		changed = true
+	}
+
+	if survivor.DeathDate == nil && duplicate.DeathDate != nil {
+		fill.DeathDate = duplicate.DeathDate
+		changed = true
+	}
+
+	if changed {
+		fill.Phonetic = phoneticKey(merged)
+	}
+
+	return fill, changed
+}
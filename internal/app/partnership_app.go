@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// ListPartnerships list all partnerships, or only
+// those of a person when an ID is given.
+func (a *Application) ListPartnerships(ctx context.Context, personID string) ([]*domain.Partnership, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListPartnerships")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	if personID != "" {
+		return a.repository.ListPartnershipsByPersonIDs(ctx, []string{personID})
+	}
+
+	return a.repository.ListPartnerships(ctx)
+}
+
+// GetPartnershipByID returns a partnership.
+func (a *Application) GetPartnershipByID(ctx context.Context, id string) (*domain.Partnership, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "GetPartnershipByID")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	return a.repository.GetPartnershipByID(ctx, id)
+}
+
+// CreatePartnership create a new partnership.
+// An empty type defaults to a marriage.
+func (a *Application) CreatePartnership(ctx context.Context, dp domain.Partnership) (string, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "CreatePartnership")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	if dp.Type == "" {
+		dp.Type = domain.PartnershipMarriage
+	}
+
+	if err := a.validatePartnership(ctx, &dp); err != nil {
+		return "", err
+	}
+
+	return a.repository.CreatePartnership(ctx, dp)
+}
+
+// UpdatePartnership replaces an existing partnership.
+func (a *Application) UpdatePartnership(ctx context.Context, dp domain.Partnership) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "UpdatePartnership")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	if dp.Type == "" {
+		dp.Type = domain.PartnershipMarriage
+	}
+
+	if err := a.validatePartnership(ctx, &dp); err != nil {
+		return err
+	}
+
+	return a.repository.UpdatePartnership(ctx, &dp)
+}
+
+// DeletePartnership deletes a partnership.
+func (a *Application) DeletePartnership(ctx context.Context, id string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "DeletePartnership")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	return a.repository.DeletePartnership(ctx, id)
+}
+
+// validatePartnership checks that a partnership is between two different
+// people who exist, and that its type, end reason and dates make sense.
+func (a *Application) validatePartnership(ctx context.Context, dp *domain.Partnership) error {
+	if dp.Person1ID == "" || dp.Person2ID == "" || dp.Person1ID == dp.Person2ID {
+		return ErrInvalidPartnership
+	}
+
+	switch dp.Type {
+	case domain.PartnershipMarriage, domain.PartnershipCivilUnion, domain.PartnershipPartnership:
+	default:
+		return ErrInvalidPartnership
+	}
+
+	switch dp.EndReason {
+	case "", domain.EndReasonDivorce, domain.EndReasonSeparation, domain.EndReasonAnnulment, domain.EndReasonDeath:
+	default:
+		return ErrInvalidPartnership
+	}
+
+	if dp.StartDate != nil && dp.EndDate != nil && dp.EndDate.Year < dp.StartDate.Year {
+		return ErrInvalidPartnership
+	}
+
+	for _, id := range []string{dp.Person1ID, dp.Person2ID} {
+		if _, err := a.repository.GetPersonByID(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
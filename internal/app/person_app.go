@@ -3,14 +3,21 @@ package app
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/bhborges/family-tree-api/internal/domain"
+	"github.com/bhborges/family-tree-api/pkg/phonetic"
 
 	"github.com/newrelic/go-agent/v3/newrelic"
 )
 
-// ListPeople return list of people.
-func (a *Application) ListPeople(ctx context.Context) ([]*domain.Person, error) {
+// MaxPeopleLimit is the largest number of people listed in a single page.
+const MaxPeopleLimit = 500
+
+// ListPeople return a page of people matching the query, sorted by name
+// unless told otherwise, and the cursor to the next page if there is one.
+// A cursor is only valid with the sort order it was given for.
+func (a *Application) ListPeople(ctx context.Context, q domain.PeopleQuery) (*domain.PeoplePage, error) {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
 		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListPeople")
@@ -19,16 +26,46 @@ func (a *Application) ListPeople(ctx context.Context) ([]*domain.Person, error)
 		defer segment.End()
 	}
 
-	p, err := a.repository.ListPeople(ctx)
+	switch q.Sort {
+	case "":
+		q.Sort = domain.SortByName
+	case domain.SortByName, domain.SortByNameDesc, domain.SortBySurname, domain.SortBySurnameDesc:
+	default:
+		return nil, ErrInvalidSort
+	}
+
+	if q.Limit < 0 || q.Limit > MaxPeopleLimit {
+		return nil, ErrInvalidLimit
+	}
+
+	if q.After != nil && q.After.Sort != q.Sort {
+		return nil, domain.ErrInvalidCursor
+	}
+
+	limit := q.Limit
+	if limit > 0 {
+		// one more person tells whether another page follows
+		q.Limit++
+	}
+
+	p, err := a.repository.ListPeople(ctx, q)
 	if err != nil {
 		return nil, err
 	}
 
-	return p, nil
+	page := &domain.PeoplePage{People: p}
+
+	if limit > 0 && len(p) > limit {
+		last := p[limit-1]
+		page.People = p[:limit]
+		page.NextCursor = domain.PeopleCursor{Sort: q.Sort, Value: q.Sort.Value(last), ID: last.ID}.String()
+	}
+
+	return page, nil
 }
 
-// GetPersonByID returns a person.
-func (a *Application) GetPersonByID(ctx context.Context, id string) (*domain.Person, error) {
+// GetPersonByID returns a person along with the relatives asked for.
+func (a *Application) GetPersonByID(ctx context.Context, id string, include ...domain.PersonInclude) (*domain.Person, error) {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
 		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "GetPerson")
@@ -37,11 +74,26 @@ func (a *Application) GetPersonByID(ctx context.Context, id string) (*domain.Per
 		defer segment.End()
 	}
 
+	want := make(map[domain.PersonInclude]bool, len(include))
+
+	for _, inc := range include {
+		switch inc {
+		case domain.PersonIncludeParents, domain.PersonIncludeChildren, domain.PersonIncludeSiblings, domain.PersonIncludeSpouse:
+			want[inc] = true
+		default:
+			return nil, ErrInvalidInclude
+		}
+	}
+
 	p, err := a.repository.GetPersonByID(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := a.populateKin(ctx, p, want); err != nil {
+		return nil, err
+	}
+
 	return p, nil
 }
 
@@ -55,6 +107,14 @@ func (a *Application) CreatePerson(ctx context.Context, dp domain.Person) (strin
 		defer segment.End()
 	}
 
+	if dp.Sex == "" {
+		dp.Sex = domain.SexUnknown
+	}
+
+	if err := validatePerson(&dp); err != nil {
+		return "", err
+	}
+
 	id, err := a.repository.CreatePerson(ctx, dp)
 	if err != nil {
 		return id, err
@@ -63,7 +123,9 @@ func (a *Application) CreatePerson(ctx context.Context, dp domain.Person) (strin
 	return id, nil
 }
 
-// CreatePeople creates multiple persons.
+// CreatePeople creates multiple persons in a single transaction: either all
+// of them are created or, wrapped in a BatchError, the error rejecting the
+// first one that could not be is returned and none is.
 func (a *Application) CreatePeople(ctx context.Context, people []domain.Person) ([]string, error) {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
@@ -73,14 +135,53 @@ func (a *Application) CreatePeople(ctx context.Context, people []domain.Person)
 		defer segment.End()
 	}
 
-	personIDs, err := a.repository.CreatePeople(ctx, people)
+	for i := range people {
+		if people[i].Sex == "" {
+			people[i].Sex = domain.SexUnknown
+		}
+
+		if err := validatePerson(&people[i]); err != nil {
+			return nil, &BatchError{Index: i, Err: err}
+		}
+	}
+
+	var personIDs []string
+
+	err := a.repository.Transaction(ctx, func(tx Repository) error {
+		var err error
+
+		personIDs, err = tx.CreatePeople(ctx, people)
+
+		return err
+	})
 	if err != nil {
-		return personIDs, err
+		return nil, err
 	}
 
 	return personIDs, nil
 }
 
+// CreatePeopleBestEffort creates multiple persons one by one,
+// returning the outcome of each instead of stopping at the first error.
+func (a *Application) CreatePeopleBestEffort(ctx context.Context, people []domain.Person) []domain.BatchResult {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "CreatePeopleBestEffort")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	results := make([]domain.BatchResult, len(people))
+
+	for i, dp := range people {
+		results[i].Index = i
+		results[i].ID, results[i].Err = a.CreatePerson(ctx, dp)
+	}
+
+	return results
+}
+
 // UpdatePerson update a person.
 func (a *Application) UpdatePerson(ctx context.Context, dp domain.Person) error {
 	trans := newrelic.FromContext(ctx)
@@ -91,13 +192,56 @@ func (a *Application) UpdatePerson(ctx context.Context, dp domain.Person) error
 		defer segment.End()
 	}
 
+	if err := validateSex(dp.Sex); err != nil {
+		return err
+	}
+
+	// names left out keep their value, the display name included, so the
+	// codes are worked out again from the names the person will have once
+	// updated, and a cleared display name from their given name and surname
+	cleared := func(field string) bool { return contains(dp.Clear, field) }
+
+	if dp.Name != "" || dp.GivenName != "" || dp.Surname != "" || dp.MaidenName != "" ||
+		cleared("name") || cleared("givenName") || cleared("surname") || cleared("maidenName") {
+		cur, err := a.repository.GetPersonByID(ctx, dp.ID)
+		if err != nil {
+			return err
+		}
+
+		for _, name := range []struct {
+			field    string
+			cur, new *string
+		}{
+			{"name", &cur.Name, &dp.Name}, {"givenName", &cur.GivenName, &dp.GivenName},
+			{"surname", &cur.Surname, &dp.Surname}, {"maidenName", &cur.MaidenName, &dp.MaidenName},
+		} {
+			switch {
+			case *name.new != "":
+				*name.cur = *name.new
+			case cleared(name.field):
+				*name.cur = ""
+			}
+		}
+
+		if cur.Name == "" {
+			cur.Name = strings.TrimSpace(cur.GivenName + " " + cur.Surname)
+			dp.Name = cur.Name
+		}
+
+		dp.Phonetic = phoneticKey(*cur)
+	}
+
 	err := a.repository.UpdatePerson(ctx, dp)
 
 	return err
 }
 
-// DeletePerson delete a person.
-func (a *Application) DeletePerson(ctx context.Context, id string) error {
+// DeletePerson moves a person to the trash as the policy tells, restrict when
+// none is given, and reports what was deleted along with them. In a dry run
+// nothing is deleted and the report tells what would be.
+func (a *Application) DeletePerson(ctx context.Context, id string, policy domain.DeletePolicy, dryRun bool) (
+	*domain.DeleteReport, error,
+) {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
 		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "DeletePerson")
@@ -106,7 +250,94 @@ func (a *Application) DeletePerson(ctx context.Context, id string) error {
 		defer segment.End()
 	}
 
-	err := a.repository.DeletePerson(ctx, id)
+	switch policy {
+	case "":
+		policy = domain.DeleteRestrict
+	case domain.DeleteRestrict, domain.DeleteDetach, domain.DeleteCascade:
+	default:
+		return nil, ErrInvalidDeletePolicy
+	}
 
-	return err
+	report := &domain.DeleteReport{People: []string{id}, DryRun: dryRun}
+
+	err := a.repository.Transaction(ctx, func(tx Repository) error {
+		if _, err := tx.GetPersonByID(ctx, id); err != nil {
+			return err
+		}
+
+		if policy == domain.DeleteCascade {
+			ds, err := newFamilyGraph(tx).descendants(ctx, id)
+			if err != nil {
+				return err
+			}
+
+			report.People = append(report.People, ds...)
+		}
+
+		rs, err := tx.ListRelationshipsByPersonIDs(ctx, report.People)
+		if err != nil {
+			return err
+		}
+
+		ps, err := tx.ListPartnershipsByPersonIDs(ctx, report.People)
+		if err != nil {
+			return err
+		}
+
+		if policy == domain.DeleteRestrict && len(rs)+len(ps) > 0 {
+			return ErrPersonHasRelatives
+		}
+
+		report.Relationships = make([]string, len(rs))
+		for i, r := range rs {
+			report.Relationships[i] = r.ID
+		}
+
+		report.Partnerships = make([]string, len(ps))
+		for i, p := range ps {
+			report.Partnerships[i] = p.ID
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		return tx.DeletePeople(ctx, report.People)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// validatePerson checks the sex of a person being created and, when they
+// have no display name, builds one from the parts of their name.
+func validatePerson(dp *domain.Person) error {
+	if err := validateSex(dp.Sex); err != nil {
+		return err
+	}
+
+	if dp.Name == "" {
+		dp.Name = strings.TrimSpace(dp.GivenName + " " + dp.Surname)
+	}
+
+	dp.Phonetic = phoneticKey(*dp)
+
+	return nil
+}
+
+// validateSex checks that the sex of a person is supported, if given.
+func validateSex(sex domain.Sex) error {
+	switch sex {
+	case "", domain.SexMale, domain.SexFemale, domain.SexOther, domain.SexUnknown:
+		return nil
+	default:
+		return ErrInvalidSex
+	}
+}
+
+// phoneticKey returns the phonetic codes of every name of a person.
+func phoneticKey(dp domain.Person) string {
+	return strings.Join(phonetic.Codes(dp.Name, dp.GivenName, dp.Surname, dp.MaidenName), " ")
 }
@@ -7,10 +7,11 @@ import (
 	"github.com/bhborges/family-tree-api/internal/domain"
 
 	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
 )
 
-// ListRelationships list all relationships
-func (a *Application) ListRelationships(ctx context.Context) ([]*domain.Relationship, error) {
+// ListRelationships list all relationships matching the given filter.
+func (a *Application) ListRelationships(ctx context.Context, f domain.RelationshipFilter) ([]*domain.Relationship, error) {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
 		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListRelationships")
@@ -19,7 +20,13 @@ func (a *Application) ListRelationships(ctx context.Context) ([]*domain.Relation
 		defer segment.End()
 	}
 
-	p, err := a.repository.ListRelationships(ctx)
+	if f.Type != "" {
+		if err := validateRelationshipType(f.Type); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := a.repository.ListRelationships(ctx, f)
 	if err != nil {
 		return nil, err
 	}
@@ -37,6 +44,14 @@ func (a *Application) CreateRelationship(ctx context.Context, dr domain.Relation
 		defer segment.End()
 	}
 
+	if dr.Type == "" {
+		dr.Type = domain.RelationshipBiological
+	}
+
+	if err := a.validateRelationship(ctx, a.repository, dr, ""); err != nil {
+		return "", err
+	}
+
 	id, err := a.repository.CreateRelationship(ctx, dr)
 	if err != nil {
 		return id, err
@@ -45,7 +60,10 @@ func (a *Application) CreateRelationship(ctx context.Context, dr domain.Relation
 	return id, nil
 }
 
-// CreateRelationships creates multiple new relationships.
+// CreateRelationships creates multiple new relationships in a single
+// transaction, each one validated against those before it. Either all of
+// them are created or, wrapped in a BatchError, the error rejecting the
+// first one that could not be is returned and none is.
 func (a *Application) CreateRelationships(ctx context.Context, drs []domain.Relationship) ([]string, error) {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
@@ -57,19 +75,57 @@ func (a *Application) CreateRelationships(ctx context.Context, drs []domain.Rela
 
 	ids := make([]string, len(drs))
 
-	for i, dr := range drs {
-		id, err := a.repository.CreateRelationship(ctx, dr)
-		if err != nil {
-			return ids, err
+	err := a.repository.Transaction(ctx, func(tx Repository) error {
+		for i, dr := range drs {
+			if dr.Type == "" {
+				dr.Type = domain.RelationshipBiological
+			}
+
+			if err := a.validateRelationship(ctx, tx, dr, ""); err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+
+			id, err := tx.CreateRelationship(ctx, dr)
+			if err != nil {
+				return &BatchError{Index: i, Err: err}
+			}
+
+			ids[i] = id
 		}
 
-		ids[i] = id
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return ids, nil
 }
 
+// CreateRelationshipsBestEffort creates multiple relationships one by one,
+// returning the outcome of each instead of stopping at the first error.
+func (a *Application) CreateRelationshipsBestEffort(ctx context.Context, drs []domain.Relationship) []domain.BatchResult {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "CreateRelationshipsBestEffort")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	results := make([]domain.BatchResult, len(drs))
+
+	for i, dr := range drs {
+		results[i].Index = i
+		results[i].ID, results[i].Err = a.CreateRelationship(ctx, dr)
+	}
+
+	return results
+}
+
 // UpdateRelationship updates an existing relationship.
+// The parent, child and type left out keep their value, and the
+// relationship is checked as it will be once updated.
 func (a *Application) UpdateRelationship(ctx context.Context, dr domain.Relationship) error {
 	trans := newrelic.FromContext(ctx)
 	if trans != nil {
@@ -79,12 +135,30 @@ func (a *Application) UpdateRelationship(ctx context.Context, dr domain.Relation
 		defer segment.End()
 	}
 
-	err := a.repository.UpdateRelationship(ctx, &dr)
-	if err != nil {
-		return err
-	}
+	return a.repository.Transaction(ctx, func(tx Repository) error {
+		current, err := tx.GetRelationshipByID(ctx, dr.ID)
+		if err != nil {
+			return err
+		}
 
-	return nil
+		if dr.ParentID == "" {
+			dr.ParentID = current.ParentID
+		}
+
+		if dr.ChildID == "" {
+			dr.ChildID = current.ChildID
+		}
+
+		if dr.Type == "" {
+			dr.Type = current.Type
+		}
+
+		if err := a.validateRelationship(ctx, tx, dr, dr.ID); err != nil {
+			return err
+		}
+
+		return tx.UpdateRelationship(ctx, &dr)
+	})
 }
 
 // DeleteRelationship deletes a relationship.
@@ -104,3 +178,117 @@ func (a *Application) DeleteRelationship(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// validateRelationship checks that a relationship can be recorded: its type
+// is supported, both people are recorded and out of the trash, nobody is
+// their own parent or ancestor, the parent and child
+// are not related yet, no child has more than two biological parents, and,
+// as the consanguinity policy tells, the biological parents of a child are
+// not close blood relatives. The relationship with the skip ID, the one
+// being updated, is ignored.
+func (a *Application) validateRelationship(ctx context.Context, repo Repository, dr domain.Relationship, skip string) error {
+	if err := validateRelationshipType(dr.Type); err != nil {
+		return err
+	}
+
+	if dr.ParentID == dr.ChildID {
+		return ErrSelfParenting
+	}
+
+	people, err := repo.ListPeopleByIDs(ctx, []string{dr.ParentID, dr.ChildID})
+	if err != nil {
+		return err
+	}
+
+	if len(people) < 2 {
+		return ErrPersonNotFound
+	}
+
+	rs, err := repo.ListRelationships(ctx, domain.RelationshipFilter{ChildID: dr.ChildID})
+	if err != nil {
+		return err
+	}
+
+	coParents := make([]string, 0, 1)
+
+	for _, r := range rs {
+		if r.ID == skip {
+			continue
+		}
+
+		if r.ParentID == dr.ParentID {
+			return ErrDuplicateRelationship
+		}
+
+		if r.Type == domain.RelationshipBiological {
+			coParents = append(coParents, r.ParentID)
+		}
+	}
+
+	if dr.Type == domain.RelationshipBiological && len(coParents) >= 2 {
+		return ErrTooManyParents
+	}
+
+	cycle, err := newFamilyGraph(repo).descendsFrom(ctx, dr.ParentID, dr.ChildID, skip)
+	if err != nil {
+		return err
+	}
+
+	if cycle {
+		return ErrRelationshipCycle
+	}
+
+	if dr.Type != domain.RelationshipBiological {
+		return nil
+	}
+
+	for _, id := range coParents {
+		if err := a.checkConsanguinity(ctx, repo, dr, id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkConsanguinity applies the consanguinity policy to the parent of a
+// relationship and the other biological parent of the child, looking up
+// their degree of kinship in a single query.
+func (a *Application) checkConsanguinity(ctx context.Context, repo Repository, dr domain.Relationship, coParent string) error {
+	policy := a.config.Consanguinity.Policy
+	if policy == ConsanguinityOff {
+		return nil
+	}
+
+	degree, related, err := repo.ConsanguinityDegree(ctx, dr.ParentID, coParent, a.config.Consanguinity.MaxDegree)
+	if err != nil {
+		return err
+	}
+
+	if !related {
+		return nil
+	}
+
+	if policy == ConsanguinityReject {
+		return ErrIncestuousOffspring
+	}
+
+	a.log.Warn(ErrIncestuousOffspring.Error(),
+		zap.String("parent", dr.ParentID),
+		zap.String("coParent", coParent),
+		zap.String("child", dr.ChildID),
+		zap.Int("degree", degree),
+	)
+
+	return nil
+}
+
+// validateRelationshipType checks that a relationship type is supported.
+func validateRelationshipType(t domain.RelationshipType) error {
+	switch t {
+	case domain.RelationshipBiological, domain.RelationshipAdoptive, domain.RelationshipStep, domain.RelationshipFoster:
+		return nil
+	default:
+		return ErrInvalidRelationshipType
+	}
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+	"github.com/bhborges/family-tree-api/pkg/phonetic"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// MaxSearchLimit is the largest number of people a search returns.
+const MaxSearchLimit = 100
+
+// defaultSearchLimit is the number of people a search returns when no limit is given.
+const defaultSearchLimit = 20
+
+// SearchPeople returns the people whose names match the query, even when
+// misspelt, ranked by how well they match it. The query is matched as
+// trigrams, as words and by the phonetic codes of its words.
+func (a *Application) SearchPeople(ctx context.Context, s domain.PersonSearch) ([]*domain.SearchResult, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "SearchPeople")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	s.Query = strings.TrimSpace(s.Query)
+	if s.Query == "" || s.BornFrom < 0 || s.BornTo < 0 || (s.BornTo > 0 && s.BornTo < s.BornFrom) {
+		return nil, ErrInvalidSearch
+	}
+
+	switch {
+	case s.Limit == 0:
+		s.Limit = defaultSearchLimit
+	case s.Limit < 0 || s.Limit > MaxSearchLimit:
+		return nil, ErrInvalidLimit
+	}
+
+	s.Codes = phonetic.Codes(s.Query)
+
+	rs, err := a.repository.SearchPeople(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+
+	return rs, nil
+}
+
+// phoneticBatchSize is the number of people read at once to work out their phonetic codes.
+const phoneticBatchSize = 500
+
+// RegisterPhoneticBackfillHook works out the phonetic codes of the people
+// without any, those recorded before search was added, the same way as for
+// people being saved. It runs in the background once the application starts,
+// until done or the application stops.
+func RegisterPhoneticBackfillHook(lc fx.Lifecycle, repository Repository, log *zap.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+
+				err := backfillPhonetic(ctx, repository, log)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					log.Error("unexpected error working out phonetic codes", zap.Error(err))
+				}
+			}()
+
+			return nil
+		},
+		OnStop: func(stop context.Context) error {
+			cancel()
+
+			select {
+			case <-done:
+			case <-stop.Done():
+			}
+
+			return nil
+		},
+	})
+}
+
+// backfillPhonetic works out the phonetic codes of the people without any,
+// a page at a time, storing those of each page at once.
+func backfillPhonetic(ctx context.Context, repository Repository, log *zap.Logger) error {
+	after, filled := "", 0
+
+	for {
+		people, err := repository.ListPeopleWithoutPhonetic(ctx, after, phoneticBatchSize)
+		if err != nil {
+			return err
+		}
+
+		codes := make(map[string]string, len(people))
+
+		for _, p := range people {
+			if c := phoneticKey(*p); c != "" {
+				codes[p.ID] = c
+			}
+		}
+
+		if err := repository.UpdatePhonetic(ctx, codes); err != nil {
+			return err
+		}
+
+		filled += len(codes)
+
+		if len(people) < phoneticBatchSize {
+			break
+		}
+
+		after = people[len(people)-1].ID
+	}
+
+	if filled > 0 {
+		log.Info("phonetic codes worked out", zap.Int("people", filled))
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/newrelic/go-agent/v3/newrelic"
+)
+
+// ListTrash returns the people deleted, and the relationships deleted on
+// their own, that can still be restored or purged.
+func (a *Application) ListTrash(ctx context.Context) (*domain.Trash, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "ListTrash")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	t, err := a.repository.ListTrash(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// RestorePerson takes a deleted person out of the trash along with the
+// descendants, relationships and partnerships deleted with them, and reports
+// what was restored. The relationships restored may link them to people
+// given other relatives in the meantime, so each of them must still follow
+// the rules relationships do, or nothing is restored.
+func (a *Application) RestorePerson(ctx context.Context, id string) (*domain.DeleteReport, error) {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "RestorePerson")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	var report *domain.DeleteReport
+
+	err := a.repository.Transaction(ctx, func(tx Repository) error {
+		var err error
+
+		report, err = tx.RestorePerson(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		for _, rid := range report.Relationships {
+			r, err := tx.GetRelationshipByID(ctx, rid)
+			if err != nil {
+				return err
+			}
+
+			if err := a.validateRelationship(ctx, tx, *r, r.ID); err != nil {
+				return fmt.Errorf("relationship %s: %w", r.ID, err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// PurgePerson deletes a person in the trash for good.
+func (a *Application) PurgePerson(ctx context.Context, id string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "PurgePerson")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	return a.repository.Transaction(ctx, func(tx Repository) error {
+		return tx.PurgePerson(ctx, id)
+	})
+}
+
+// RestoreRelationship takes a deleted relationship out of the trash, provided
+// it can still be recorded alongside those recorded since it was deleted.
+func (a *Application) RestoreRelationship(ctx context.Context, id string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "RestoreRelationship")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	return a.repository.Transaction(ctx, func(tx Repository) error {
+		r, err := tx.GetDeletedRelationship(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := a.validateRelationship(ctx, tx, *r, r.ID); err != nil {
+			return err
+		}
+
+		return tx.RestoreRelationship(ctx, id)
+	})
+}
+
+// PurgeRelationship deletes a relationship in the trash for good.
+func (a *Application) PurgeRelationship(ctx context.Context, id string) error {
+	trans := newrelic.FromContext(ctx)
+	if trans != nil {
+		segmentName := fmt.Sprintf("%s:%s", _SegmentPrefix, "PurgeRelationship")
+		segment := trans.StartSegment(segmentName)
+
+		defer segment.End()
+	}
+
+	return a.repository.PurgeRelationship(ctx, id)
+}
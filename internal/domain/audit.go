@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// AuditAction tells what a change recorded in the audit log did.
+type AuditAction string
+
+const (
+	// AuditCreate records an entity being created.
+	AuditCreate AuditAction = "create"
+	// AuditUpdate records an entity being updated.
+	AuditUpdate AuditAction = "update"
+	// AuditDelete records an entity being moved to the trash.
+	AuditDelete AuditAction = "delete"
+	// AuditRestore records an entity being restored from the trash.
+	AuditRestore AuditAction = "restore"
+	// AuditPurge records an entity being deleted for good.
+	AuditPurge AuditAction = "purge"
+)
+
+// AuditEntry is a change to a person, relationship or partnership, as
+// recorded in the audit log by the transaction making it. Before and After
+// hold the entity as stored before and after the change, the former missing
+// when it was created and the latter when it was deleted for good.
+type AuditEntry struct {
+	ID        int64           `json:"id"`
+	At        time.Time       `json:"at"`
+	Actor     string          `json:"actor,omitempty"`
+	RequestID string          `json:"requestId,omitempty"`
+	Entity    string          `json:"entity"`
+	EntityID  string          `json:"entityId"`
+	Action    AuditAction     `json:"action"`
+	Before    json.RawMessage `json:"before,omitempty"`
+	After     json.RawMessage `json:"after,omitempty"`
+}
+
+// Auditor tells who is making a change, and on which request,
+// for the audit log to record it.
+type Auditor struct {
+	Actor     string
+	RequestID string
+}
+
+type auditorKey struct{}
+
+// WithAuditor returns a copy of ctx carrying the given auditor.
+func WithAuditor(ctx context.Context, a Auditor) context.Context {
+	return context.WithValue(ctx, auditorKey{}, a)
+}
+
+// AuditorFromContext returns the auditor carried by ctx, if any.
+func AuditorFromContext(ctx context.Context) Auditor {
+	a, _ := ctx.Value(auditorKey{}).(Auditor)
+
+	return a
+}
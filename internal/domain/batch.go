@@ -0,0 +1,32 @@
+package domain
+
+// BatchResult is the outcome of creating one item of a batch in best
+// effort mode: the ID the item was given, or the error rejecting it.
+type BatchResult struct {
+	Index int
+	ID    string
+	Err   error
+}
+
+// FamilyBatch is a set of new people and the relationships between them,
+// or with people already recorded, created together. Relationships refer
+// to the new people by the temporary keys the batch gives them, and to
+// people already recorded by their ID.
+type FamilyBatch struct {
+	People        []BatchPerson  `json:"people"`
+	Relationships []Relationship `json:"relationships"`
+}
+
+// BatchPerson is a new person of a FamilyBatch and its temporary key.
+type BatchPerson struct {
+	Key string `json:"key,omitempty"`
+	Person
+}
+
+// FamilyBatchResult holds the IDs given to the people and relationships
+// of a FamilyBatch, in order, and the ID each temporary key stood for.
+type FamilyBatchResult struct {
+	People        []string          `json:"people"`
+	Keys          map[string]string `json:"keys"`
+	Relationships []string          `json:"relationships"`
+}
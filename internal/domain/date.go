@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidDate occurs when a partial date cannot be parsed.
+var ErrInvalidDate = errors.New("invalid date")
+
+// DateQualifier tells how a partial date relates to the actual date.
+type DateQualifier string
+
+const (
+	// DateExact means the date is known to the given precision.
+	DateExact DateQualifier = ""
+	// DateAbout means the actual date is close to the given one.
+	DateAbout DateQualifier = "about"
+	// DateEstimated means the date was worked out from other facts.
+	DateEstimated DateQualifier = "estimated"
+	// DateBefore means the actual date is before the given one.
+	DateBefore DateQualifier = "before"
+	// DateAfter means the actual date is after the given one.
+	DateAfter DateQualifier = "after"
+)
+
+// dateQualifiers maps the words accepted before a date to their qualifier.
+//
+//nolint:gochecknoglobals
+var dateQualifiers = map[string]DateQualifier{
+	"about": DateAbout, "abt": DateAbout, "circa": DateAbout, "ca": DateAbout, "c.": DateAbout,
+	"estimated": DateEstimated, "est": DateEstimated, "cal": DateEstimated,
+	"before": DateBefore, "bef": DateBefore,
+	"after": DateAfter, "aft": DateAfter,
+}
+
+// gedcomMonths holds the month abbreviations used by GEDCOM dates.
+//
+//nolint:gochecknoglobals
+var gedcomMonths = []string{"JAN", "FEB", "MAR", "APR", "MAY", "JUN", "JUL", "AUG", "SEP", "OCT", "NOV", "DEC"}
+
+// PartialDate is a date that may only be known in part, such as a year
+// or a month, and possibly only approximately, as in "about 1850" or
+// "before 1902". Month and Day are zero when unknown.
+type PartialDate struct {
+	Qualifier DateQualifier
+	Year      int
+	Month     int
+	Day       int
+}
+
+// ParsePartialDate reads a date written as an optional qualifier followed by
+// either an ISO date (1850, 1850-03, 1850-03-12) or a GEDCOM one (MAR 1850,
+// 12 MAR 1850). Qualifiers may be spelled out or abbreviated GEDCOM style,
+// so both "about 1850" and "ABT 1850" are accepted.
+func ParsePartialDate(s string) (PartialDate, error) {
+	var d PartialDate
+
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return d, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+	}
+
+	if q, ok := dateQualifiers[strings.ToLower(fields[0])]; ok {
+		d.Qualifier = q
+		fields = fields[1:]
+	}
+
+	var err error
+
+	switch len(fields) {
+	case 1:
+		err = d.parseISO(fields[0])
+	case 2:
+		d.Month = gedcomMonth(fields[0])
+		d.Year, err = strconv.Atoi(fields[1])
+	case 3:
+		d.Month = gedcomMonth(fields[1])
+		d.Year, err = strconv.Atoi(fields[2])
+
+		if err == nil {
+			d.Day, err = strconv.Atoi(fields[0])
+		}
+	default:
+		return d, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+	}
+
+	if err != nil || !d.valid() {
+		return d, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+	}
+
+	return d, nil
+}
+
+// parseISO reads the year, month and day of a YYYY[-MM[-DD]] date.
+func (d *PartialDate) parseISO(s string) error {
+	parts := strings.Split(s, "-")
+	if len(parts) > 3 {
+		return ErrInvalidDate
+	}
+
+	n := make([]int, len(parts))
+
+	for i, p := range parts {
+		v, err := strconv.Atoi(p)
+		if err != nil {
+			return err
+		}
+
+		n[i] = v
+	}
+
+	d.Year = n[0]
+
+	if len(n) > 1 {
+		d.Month = n[1]
+	}
+
+	if len(n) > 2 {
+		d.Day = n[2]
+	}
+
+	return nil
+}
+
+// gedcomMonth returns the number of a GEDCOM month abbreviation, or -1.
+func gedcomMonth(s string) int {
+	for i, m := range gedcomMonths {
+		if strings.EqualFold(m, s) {
+			return i + 1
+		}
+	}
+
+	return -1
+}
+
+// valid reports whether the date exists in the calendar.
+func (d PartialDate) valid() bool {
+	if d.Year <= 0 || d.Month < 0 || d.Month > 12 || d.Day < 0 || (d.Day > 0 && d.Month == 0) {
+		return false
+	}
+
+	if d.Day == 0 {
+		return true
+	}
+
+	t := time.Date(d.Year, time.Month(d.Month), d.Day, 0, 0, 0, 0, time.UTC)
+
+	return t.Day() == d.Day
+}
+
+// String returns the date as its qualifier followed by an ISO date,
+// as in "about 1850" or "before 1902-03".
+func (d PartialDate) String() string {
+	s := fmt.Sprintf("%04d", d.Year)
+
+	if d.Month > 0 {
+		s += fmt.Sprintf("-%02d", d.Month)
+	}
+
+	if d.Day > 0 {
+		s += fmt.Sprintf("-%02d", d.Day)
+	}
+
+	if d.Qualifier != DateExact {
+		s = string(d.Qualifier) + " " + s
+	}
+
+	return s
+}
+
+// GEDCOM returns the date as written in GEDCOM files, as in "ABT 1850".
+func (d PartialDate) GEDCOM() string {
+	s := strconv.Itoa(d.Year)
+
+	if d.Month > 0 {
+		s = gedcomMonths[d.Month-1] + " " + s
+	}
+
+	if d.Day > 0 {
+		s = strconv.Itoa(d.Day) + " " + s
+	}
+
+	switch d.Qualifier {
+	case DateAbout:
+		s = "ABT " + s
+	case DateEstimated:
+		s = "EST " + s
+	case DateBefore:
+		s = "BEF " + s
+	case DateAfter:
+		s = "AFT " + s
+	case DateExact:
+	}
+
+	return s
+}
+
+// MarshalJSON encodes the date as a string.
+func (d PartialDate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes a date from a string.
+func (d *PartialDate) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+
+	v, err := ParsePartialDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+
+	return nil
+}
+
+// MarshalText encodes the date as text, which is how it appears in XML.
+func (d PartialDate) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText decodes a date from text.
+func (d *PartialDate) UnmarshalText(b []byte) error {
+	v, err := ParsePartialDate(string(b))
+	if err != nil {
+		return err
+	}
+
+	*d = v
+
+	return nil
+}
+
+// Value stores the date as a string.
+func (d PartialDate) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a date stored as a string.
+func (d *PartialDate) Scan(src interface{}) error {
+	var s string
+
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidDate, src)
+	}
+
+	v, err := ParsePartialDate(s)
+	if err != nil {
+		return err
+	}
+
+	*d = v
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParsePartialDate(t *testing.T) {
+	for s, date := range map[string]PartialDate{
+		"1850":           {Year: 1850},
+		"1850-03":        {Year: 1850, Month: 3},
+		"1850-03-12":     {Year: 1850, Month: 3, Day: 12},
+		"about 1850":     {Qualifier: DateAbout, Year: 1850},
+		"ABT 1850":       {Qualifier: DateAbout, Year: 1850},
+		"bef 1902-03":    {Qualifier: DateBefore, Year: 1902, Month: 3},
+		"MAR 1850":       {Year: 1850, Month: 3},
+		"12 MAR 1850":    {Year: 1850, Month: 3, Day: 12},
+		"AFT 1 jan 1900": {Qualifier: DateAfter, Year: 1900, Month: 1, Day: 1},
+		"1852-02-29":     {Year: 1852, Month: 2, Day: 29},
+	} {
+		d, err := ParsePartialDate(s)
+
+		assert.NoError(t, err, s)
+		assert.Equal(t, date, d, s)
+	}
+}
+
+func Test_ParsePartialDate_Invalid(t *testing.T) {
+	for _, s := range []string{
+		"", "about", "1850-02-30", "1851-02-29", "1850-13", "0", "12 1850", "MAR", "12 FOO 1850",
+		"about 12 MAR 1850 noon", "1850-03-12-01",
+	} {
+		_, err := ParsePartialDate(s)
+
+		assert.ErrorIs(t, err, ErrInvalidDate, s)
+	}
+}
+
+func Test_PartialDate_RoundTrip(t *testing.T) {
+	for _, s := range []string{"1850", "about 1850-03", "before 1902-03-12", "estimated 1790", "after 1900-01-01"} {
+		d, err := ParsePartialDate(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, s, d.String())
+
+		g, err := ParsePartialDate(d.GEDCOM())
+		assert.NoError(t, err, d.GEDCOM())
+		assert.Equal(t, d, g, d.GEDCOM())
+	}
+
+	d := PartialDate{Qualifier: DateAbout, Year: 1850, Month: 3, Day: 12}
+	assert.Equal(t, "ABT 12 MAR 1850", d.GEDCOM())
+	assert.Equal(t, "about 1850-03-12", d.String())
+}
@@ -0,0 +1,46 @@
+package domain
+
+import "time"
+
+// DeletePolicy tells what happens to the relatives of a person being deleted.
+type DeletePolicy string
+
+const (
+	// DeleteRestrict refuses to delete a person with any relationship or partnership.
+	DeleteRestrict DeletePolicy = "restrict"
+	// DeleteDetach deletes the relationships and partnerships of a person along with them.
+	DeleteDetach DeletePolicy = "detach"
+	// DeleteCascade deletes the descendants of a person by birth or adoption
+	// along with them, and the relationships and partnerships of all of them,
+	// detaching their step and foster children.
+	DeleteCascade DeletePolicy = "cascade"
+)
+
+// DeleteReport lists the IDs of the people, relationships and partnerships
+// moved to the trash along with a person or, in a dry run, that would be.
+// It also lists those restored from the trash along with a person.
+type DeleteReport struct {
+	People        []string `json:"people"`
+	Relationships []string `json:"relationships"`
+	Partnerships  []string `json:"partnerships"`
+	DryRun        bool     `json:"dryRun"`
+}
+
+// Trash holds the people deleted, and the relationships deleted on their own,
+// that can still be restored or purged for good.
+type Trash struct {
+	People        []*TrashedPerson       `json:"people"`
+	Relationships []*TrashedRelationship `json:"relationships"`
+}
+
+// TrashedPerson is a person in the trash, deleted at DeletedAt.
+type TrashedPerson struct {
+	*Person
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// TrashedRelationship is a relationship in the trash, deleted at DeletedAt.
+type TrashedRelationship struct {
+	*Relationship
+	DeletedAt time.Time `json:"deletedAt"`
+}
@@ -1,39 +1,158 @@
 // Package domain holds all domain related code.
 package domain
 
+import (
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Sex represents the sex of a person.
+type Sex string
+
+const (
+	// SexMale is the sex of a male person.
+	SexMale Sex = "male"
+	// SexFemale is the sex of a female person.
+	SexFemale Sex = "female"
+	// SexOther is the sex of a person who is neither male nor female.
+	SexOther Sex = "other"
+	// SexUnknown is used when the sex of a person is not known.
+	SexUnknown Sex = "unknown"
+)
+
 // Person represents a person or member.
+// Name is how the person is displayed, while GivenName, Surname and
+// MaidenName hold the parts of their name when known. Phonetic holds
+// the codes of how their names sound, used to search for them, and
+// DeletedAt when they were moved to the trash. Clear names the fields,
+// as spelled in JSON, that a partial update clears.
 type Person struct {
-	ID          string         `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	Name        string         `json:"name,omitempty"`
-	Parents     []*Person      `json:"parents,omitempty" gorm:"many2many:relationships;ForeignKey:ID;References:id"`
-	Children    []*Person      `json:"children,omitempty" gorm:"many2many:relationships;ForeignKey:ID;References:id"`
-	Siblings    []*Person      `json:"siblings,omitempty" gorm:"-"`
-	Spouse      *Person        `json:"spouse,omitempty" gorm:"-"`
-	BaconNumber int            `json:"baconNumber,omitempty" gorm:"-"`
+	ID           string         `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Name         string         `json:"name,omitempty"`
+	GivenName    string         `json:"givenName,omitempty"`
+	Surname      string         `json:"surname,omitempty"`
+	MaidenName   string         `json:"maidenName,omitempty"`
+	Sex          Sex            `json:"sex,omitempty" gorm:"default:unknown"`
+	BirthDate    *PartialDate   `json:"birthDate,omitempty"`
+	BirthPlace   string         `json:"birthPlace,omitempty"`
+	DeathDate    *PartialDate   `json:"deathDate,omitempty"`
+	DeathPlace   string         `json:"deathPlace,omitempty"`
+	Phonetic     string         `json:"-" xml:"-"`
+	DeletedAt    gorm.DeletedAt `json:"-" xml:"-"`
+	Parents      []*Person      `json:"parents,omitempty" gorm:"many2many:relationships;ForeignKey:ID;References:id"`
+	Children     []*Person      `json:"children,omitempty" gorm:"many2many:relationships;ForeignKey:ID;References:id"`
+	Siblings     []*Person      `json:"siblings,omitempty" gorm:"-"`
+	HalfSiblings []*Person      `json:"halfSiblings,omitempty" gorm:"-"`
+	StepSiblings []*Person      `json:"stepSiblings,omitempty" gorm:"-"`
+	Spouse       *Person        `json:"spouse,omitempty" gorm:"-"`
+	Partnerships []*Partnership `json:"partnerships,omitempty" gorm:"-"`
+	BaconNumber  int            `json:"baconNumber,omitempty" gorm:"-"`
+	Clear        []string       `json:"-" xml:"-" gorm:"-"`
+}
+
+// RelationshipType tells how a parent came to be the parent of a child.
+type RelationshipType string
+
+const (
+	// RelationshipBiological links a child to their birth parent.
+	RelationshipBiological RelationshipType = "biological"
+	// RelationshipAdoptive links a child to a parent who adopted them.
+	RelationshipAdoptive RelationshipType = "adoptive"
+	// RelationshipStep links a child to the partner of one of their parents.
+	RelationshipStep RelationshipType = "step"
+	// RelationshipFoster links a child to a parent fostering them.
+	RelationshipFoster RelationshipType = "foster"
+)
+
+// Kin reports whether the relationship makes the parent and the child
+// relatives by descent, which adoption does as much as birth.
+func (t RelationshipType) Kin() bool {
+	return t == "" || t == RelationshipBiological || t == RelationshipAdoptive
 }
 
 // Relationship represents a many-to-many relationship between two persons.
+// DeletedAt is set while it is in the trash.
 type Relationship struct {
-	ID        string         `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
-	ParentID  string         `json:"parent" gorm:"primaryKey"`
-	ChildID   string         `json:"children" gorm:"primaryKey"`
+	ID        string           `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ParentID  string           `json:"parent" gorm:"primaryKey"`
+	ChildID   string           `json:"children" gorm:"primaryKey"`
+	Type      RelationshipType `json:"type,omitempty" gorm:"default:biological"`
+	DeletedAt gorm.DeletedAt   `json:"-" xml:"-"`
+}
+
+// RelationshipFilter narrows down a list of relationships.
+// Empty fields match every relationship.
+type RelationshipFilter struct {
+	Type     RelationshipType
+	ParentID string
+	ChildID  string
 }
 
+// PersonInclude names the relatives read along with a person.
+type PersonInclude string
+
+const (
+	// PersonIncludeParents reads the parents of a person.
+	PersonIncludeParents PersonInclude = "parents"
+	// PersonIncludeChildren reads the children of a person.
+	PersonIncludeChildren PersonInclude = "children"
+	// PersonIncludeSiblings reads the full, half and step siblings of a person.
+	PersonIncludeSiblings PersonInclude = "siblings"
+	// PersonIncludeSpouse reads the partnerships and spouse of a person.
+	PersonIncludeSpouse PersonInclude = "spouse"
+)
+
 // Cousins represents the cousin relationship between two persons.
+// Degree is 1 for first cousins, 2 for second cousins and so on, while
+// Removed counts the generations between them.
 type Cousins struct {
 	Person1 Person   `json:"person1"`
 	Person2 Person   `json:"person2"`
 	Shared  []Person `json:"shared"`
+	Degree  int      `json:"degree"`
+	Removed int      `json:"removed"`
 }
 
 // BaconNumber represents the bacon's number between two persons.
+// Path holds the chain of people connecting them, both ends included.
 type BaconNumber struct {
-	Person1   Person `json:"person1"`
-	Person2   Person `json:"person2"`
-	Number    int    `json:"number"`
-	Path      []int  `json:"path"`
-	Visited   []bool `json:"-"`
-	Processed []bool `json:"-"`
+	Person1 Person    `json:"person1"`
+	Person2 Person    `json:"person2"`
+	Number  int       `json:"number"`
+	Path    []*Person `json:"path"`
+}
+
+// Kinship represents how a person is related to another. Term describes
+// Person1 from the point of view of Person2, and Path holds the people
+// connecting them that justify it.
+type Kinship struct {
+	Person1 Person    `json:"person1"`
+	Person2 Person    `json:"person2"`
+	Term    string    `json:"term"`
+	Path    []*Person `json:"path"`
+}
+
+// TreeMode selects which relatives of the root person a family tree holds.
+type TreeMode string
+
+const (
+	// TreeModeAncestors holds the root person and their ancestors.
+	TreeModeAncestors TreeMode = "ancestors"
+	// TreeModeDescendants holds the root person and their descendants.
+	TreeModeDescendants TreeMode = "descendants"
+	// TreeModeHourglass holds both the ancestors and descendants of the root person.
+	TreeModeHourglass TreeMode = "hourglass"
+)
+
+// TreeOptions holds how a family tree is built. Generations limits how
+// far from the root person the tree goes, with zero meaning as far as allowed.
+// A non-zero AsOf builds the tree as it was at that moment.
+type TreeOptions struct {
+	Mode        TreeMode
+	Generations int
+	AsOf        time.Time
 }
 
 // FamilyTree represents a collection of family members.
@@ -42,13 +161,54 @@ type FamilyTree struct {
 }
 
 // Member represents a family member.
+// Generation is relative to the root person: negative for
+// ancestors, zero for the root and positive for descendants.
 type Member struct {
+	ID            string               `json:"id"`
 	Name          string               `json:"name"`
+	Generation    int                  `json:"generation"`
 	Relationships []FamilyRelationship `json:"relationships"`
 }
 
 // FamilyRelationship represents the relationship between two family members.
+// Type tells how a parent or child is related when they are one.
 type FamilyRelationship struct {
-	Name         string `json:"name"`
-	Relationship string `json:"relationship"`
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Relationship string           `json:"relationship"`
+	Type         RelationshipType `json:"type,omitempty"`
+}
+
+// SortRelationships orders the relationships of a member by kind, then by
+// the name and ID of the relative, so that trees come out the same each time.
+func (m *Member) SortRelationships() {
+	sort.Slice(m.Relationships, func(i, j int) bool {
+		a, b := m.Relationships[i], m.Relationships[j]
+		if a.Relationship != b.Relationship {
+			return a.Relationship < b.Relationship
+		}
+
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+
+		return a.ID < b.ID
+	})
+}
+
+// ImportReport describes the outcome of an import.
+// People maps the identifiers used in the imported file to the IDs of
+// the people created from them.
+type ImportReport struct {
+	People        map[string]string `json:"people"`
+	Relationships int               `json:"relationships"`
+	Partnerships  int               `json:"partnerships"`
+	Warnings      []ImportWarning   `json:"warnings"`
+}
+
+// ImportWarning describes a record that was imported partially or skipped.
+type ImportWarning struct {
+	XRef    string `json:"xref,omitempty"`
+	Line    int    `json:"line"`
+	Message string `json:"message"`
 }
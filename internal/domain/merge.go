@@ -0,0 +1,38 @@
+package domain
+
+import "time"
+
+// DuplicateQuery selects the pairs of people that may be the same person,
+// all of them or only those including PersonID, scoring at least MinScore.
+type DuplicateQuery struct {
+	PersonID string
+	MinScore float64
+	Limit    int
+}
+
+// DuplicateCandidate is a pair of people that may be the same person.
+// NameScore tells how alike their names are, from 0 to 1, and
+// SharedRelatives how many parents, children and partners they share.
+// Score adds both up, a quarter for each shared relative up to four.
+type DuplicateCandidate struct {
+	Person1         *Person `json:"person1"`
+	Person2         *Person `json:"person2"`
+	Score           float64 `json:"score"`
+	NameScore       float64 `json:"nameScore"`
+	SharedRelatives int     `json:"sharedRelatives"`
+}
+
+// PersonMerge records a duplicate person merged into the one surviving
+// them: the relationships and partnerships moved over to the survivor,
+// and those dropped for repeating one the survivor already had.
+type PersonMerge struct {
+	ID                   string    `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	SurvivorID           string    `json:"survivor"`
+	DuplicateID          string    `json:"duplicate"`
+	DuplicateName        string    `json:"duplicateName"`
+	RelationshipsMoved   int       `json:"relationshipsMoved"`
+	RelationshipsDropped int       `json:"relationshipsDropped"`
+	PartnershipsMoved    int       `json:"partnershipsMoved"`
+	PartnershipsDropped  int       `json:"partnershipsDropped"`
+	MergedAt             time.Time `json:"mergedAt" gorm:"default:now()"`
+}
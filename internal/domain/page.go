@@ -0,0 +1,94 @@
+package domain
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ErrInvalidCursor occurs when a page cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// PeopleSort is the order people are listed in: a field, ascending,
+// or descending when prefixed with a minus sign. Ties are broken by ID.
+type PeopleSort string
+
+const (
+	// SortByName lists people by name, A to Z.
+	SortByName PeopleSort = "name"
+	// SortByNameDesc lists people by name, Z to A.
+	SortByNameDesc PeopleSort = "-name"
+	// SortBySurname lists people by surname, A to Z.
+	SortBySurname PeopleSort = "surname"
+	// SortBySurnameDesc lists people by surname, Z to A.
+	SortBySurnameDesc PeopleSort = "-surname"
+)
+
+// Field returns the field people are sorted by.
+func (s PeopleSort) Field() string {
+	return strings.TrimPrefix(string(s), "-")
+}
+
+// Desc reports whether people are sorted in descending order.
+func (s PeopleSort) Desc() bool {
+	return strings.HasPrefix(string(s), "-")
+}
+
+// Value returns the value of the sort field of a person.
+func (s PeopleSort) Value(p *Person) string {
+	if s.Field() == string(SortBySurname) {
+		return p.Surname
+	}
+
+	return p.Name
+}
+
+// PeopleQuery selects a page of people. Name matches people whose name
+// contains it and Surname those with that surname, both ignoring case.
+// A Limit of zero lists every person after the cursor, if any.
+type PeopleQuery struct {
+	Name    string
+	Surname string
+	Sort    PeopleSort
+	Limit   int
+	After   *PeopleCursor
+}
+
+// PeopleCursor marks the last person of a page, the next
+// page starting right after it in the order it was sorted by.
+type PeopleCursor struct {
+	Sort  PeopleSort `json:"s"`
+	Value string     `json:"v"`
+	ID    string     `json:"id"`
+}
+
+// String encodes a cursor as an opaque string safe to use in URLs.
+func (c PeopleCursor) String() string {
+	b, _ := json.Marshal(c)
+
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParsePeopleCursor decodes a cursor encoded by PeopleCursor.String.
+func ParsePeopleCursor(s string) (PeopleCursor, error) {
+	var c PeopleCursor
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, ErrInvalidCursor
+	}
+
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return c, ErrInvalidCursor
+	}
+
+	return c, nil
+}
+
+// PeoplePage is a page of people and, when more follow,
+// the cursor to the next page.
+type PeoplePage struct {
+	People     []*Person
+	NextCursor string
+}
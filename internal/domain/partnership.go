@@ -0,0 +1,57 @@
+package domain
+
+import "gorm.io/gorm"
+
+// PartnershipType tells what kind of union a partnership is.
+type PartnershipType string
+
+const (
+	// PartnershipMarriage is a marriage.
+	PartnershipMarriage PartnershipType = "marriage"
+	// PartnershipCivilUnion is a civil union.
+	PartnershipCivilUnion PartnershipType = "civil-union"
+	// PartnershipPartnership is an informal partnership.
+	PartnershipPartnership PartnershipType = "partnership"
+)
+
+// PartnershipEndReason tells why a partnership ended.
+type PartnershipEndReason string
+
+const (
+	// EndReasonDivorce ends a partnership by divorce or dissolution.
+	EndReasonDivorce PartnershipEndReason = "divorce"
+	// EndReasonSeparation ends a partnership by separation.
+	EndReasonSeparation PartnershipEndReason = "separation"
+	// EndReasonAnnulment ends a partnership by annulment.
+	EndReasonAnnulment PartnershipEndReason = "annulment"
+	// EndReasonDeath ends a partnership by the death of a partner.
+	EndReasonDeath PartnershipEndReason = "death"
+)
+
+// Partnership represents a marriage or partnership between two persons.
+// A partnership is current until it has an end date or an end reason.
+// DeletedAt is set while it is in the trash, along with a partner.
+type Partnership struct {
+	ID        string               `json:"id" gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	Person1ID string               `json:"person1"`
+	Person2ID string               `json:"person2"`
+	Type      PartnershipType      `json:"type,omitempty" gorm:"default:marriage"`
+	StartDate *PartialDate         `json:"startDate,omitempty"`
+	EndDate   *PartialDate         `json:"endDate,omitempty"`
+	EndReason PartnershipEndReason `json:"endReason,omitempty"`
+	DeletedAt gorm.DeletedAt       `json:"-" xml:"-"`
+}
+
+// Current reports whether the partnership has not ended.
+func (p *Partnership) Current() bool {
+	return p.EndDate == nil && p.EndReason == ""
+}
+
+// Partner returns the ID of the partner of the given person.
+func (p *Partnership) Partner(id string) string {
+	if p.Person1ID == id {
+		return p.Person2ID
+	}
+
+	return p.Person1ID
+}
@@ -0,0 +1,18 @@
+package domain
+
+// PersonSearch looks up people by a possibly misspelt name, and
+// optionally by the range of years they were born in, zero for none.
+type PersonSearch struct {
+	Query    string
+	Codes    []string
+	BornFrom int
+	BornTo   int
+	Limit    int
+}
+
+// SearchResult is a person found by a search, along with how well they
+// match it. Results are ranked by score, the best match first.
+type SearchResult struct {
+	Person *Person `json:"person"`
+	Score  float64 `json:"score"`
+}
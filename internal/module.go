@@ -15,6 +15,8 @@ func APIModule() fx.Option {
 		fx.Provide(
 			fx.Annotate(adapter.NewPostgresRepository, fx.As(new(app.Repository))),
 		),
+		fx.Provide(app.ProvideConfig),
+		fx.Invoke(app.RegisterPhoneticBackfillHook),
 		fx.Provide(
 			fx.Annotate(app.NewApplication, fx.As(new(rest.Application))),
 		),
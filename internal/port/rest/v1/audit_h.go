@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/go-chi/render"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// actorHeader names who makes a request, for the audit log to record.
+const actorHeader = "X-Actor"
+
+// auditorMiddleware hands the actor and the ID of a request over to the
+// audit log of the changes it makes.
+func auditorMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := domain.WithAuditor(r.Context(), domain.Auditor{
+			Actor:     r.Header.Get(actorHeader),
+			RequestID: middleware.GetReqID(r.Context()),
+		})
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// PersonHistory returns the changes made to a person and to the
+// relationships and partnerships they were part of, oldest first.
+func (h *HTTPServer) PersonHistory(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	entries, err := h.application.PersonHistory(r.Context(), id)
+
+	if errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving person history from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, entries)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(entries)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, entries)
+	}
+}
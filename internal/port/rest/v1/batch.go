@@ -0,0 +1,98 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/go-chi/render"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// Batch modes, chosen with the mode query parameter. In atomic mode, the
+// default, a batch is created in a single transaction and nothing is left
+// when any item fails. In best effort mode every item is tried on its own.
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best-effort"
+)
+
+// batchResult is the outcome of one item of a batch created in best effort
+// mode: the ID it was given, or the error rejecting it and its code.
+type batchResult struct {
+	Index int    `json:"index"`
+	ID    string `json:"id,omitempty"`
+	Error string `json:"error,omitempty"`
+	Code  string `json:"code,omitempty"`
+}
+
+// bestEffort reads the batch mode of a request, reporting whether it
+// is best effort and, rendering a bad request otherwise, if it is valid.
+func bestEffort(w http.ResponseWriter, r *http.Request) (bool, bool) {
+	switch r.URL.Query().Get("mode") {
+	case "", batchModeAtomic:
+		return false, true
+	case batchModeBestEffort:
+		return true, true
+	default:
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, "invalid batch mode")
+
+		return false, false
+	}
+}
+
+// renderBatchError writes the response of an error rejecting an item of a
+// batch created in atomic mode and reports whether err was one of them.
+func renderBatchError(w http.ResponseWriter, r *http.Request, err error) bool {
+	status, code, ok := errorCode(err)
+	if !ok {
+		return false
+	}
+
+	resp := errorResponse{Code: code, Message: err.Error()}
+
+	var be *app.BatchError
+	if errors.As(err, &be) {
+		resp.Field, resp.Index = be.Field, &be.Index
+	}
+
+	render.Status(r, status)
+	render.JSON(w, r, resp)
+
+	return true
+}
+
+// renderBatchResults writes the outcome of each item of a batch created in
+// best effort mode, with a 201 status when all were created and 207 otherwise.
+// Unexpected errors are logged and reported with the internal_error code.
+func (h *HTTPServer) renderBatchResults(w http.ResponseWriter, r *http.Request, results []domain.BatchResult) {
+	status := http.StatusCreated
+	resp := make([]batchResult, 0, len(results))
+
+	for _, res := range results {
+		br := batchResult{Index: res.Index, ID: res.ID}
+
+		if res.Err != nil {
+			status = http.StatusMultiStatus
+			br.ID = ""
+
+			if _, code, ok := errorCode(res.Err); ok {
+				br.Error, br.Code = res.Err.Error(), code
+			} else {
+				newrelic.FromContext(r.Context()).NoticeError(res.Err)
+				h.log.Error("unexpected error creating batch item from API", zap.Int("index", res.Index), zap.Error(res.Err))
+
+				br.Error, br.Code = "unexpected error", "internal_error"
+			}
+		}
+
+		resp = append(resp, br)
+	}
+
+	render.Status(r, status)
+	render.JSON(w, r, resp)
+}
@@ -0,0 +1,43 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/go-chi/render"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// CreateFamilyBatch creates new people and the relationships between them,
+// referred to by temporary keys, all of them or none.
+func (h *HTTPServer) CreateFamilyBatch(w http.ResponseWriter, r *http.Request) {
+	var b domain.FamilyBatch
+
+	if err := json.NewDecoder(r.Body).Decode(&b); err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error decoding data", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	res, err := h.application.CreateFamilyBatch(r.Context(), b)
+
+	if renderBatchError(w, r, err) {
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error creating family batch from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, res)
+}
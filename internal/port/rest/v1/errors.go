@@ -0,0 +1,92 @@
+package rest
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/go-chi/render"
+)
+
+// errorResponse is the body of an error response. Code is a stable,
+// machine-readable identifier of the error, and Message describes it.
+// Index, when set, is the index of the item of a batch the error rejected,
+// and Field the field of the batch holding it, if there are several.
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+	Index   *int   `json:"index,omitempty"`
+}
+
+// errorMapping maps an error to the status and code of its response.
+type errorMapping struct {
+	err    error
+	status int
+	code   string
+}
+
+// relationshipErrors lists the errors rejecting a relationship,
+// with the status and code of their response.
+//
+//nolint:gochecknoglobals
+var relationshipErrors = []errorMapping{
+	{app.ErrPersonNotFound, http.StatusNotFound, "person_not_found"},
+	{app.ErrInvalidRelationshipType, http.StatusUnprocessableEntity, "invalid_relationship_type"},
+	{app.ErrSelfParenting, http.StatusUnprocessableEntity, "self_parenting"},
+	{app.ErrRelationshipCycle, http.StatusUnprocessableEntity, "relationship_cycle"},
+	{app.ErrIncestuousOffspring, http.StatusUnprocessableEntity, "incestuous_offspring"},
+	{app.ErrTooManyParents, http.StatusConflict, "too_many_biological_parents"},
+	{app.ErrDuplicateRelationship, http.StatusConflict, "duplicate_relationship"},
+}
+
+// personErrors lists the errors rejecting a person,
+// with the status and code of their response.
+//
+//nolint:gochecknoglobals
+var personErrors = []errorMapping{
+	{app.ErrInvalidSex, http.StatusUnprocessableEntity, "invalid_sex"},
+}
+
+// batchErrors lists the errors rejecting an item of a batch of people
+// and relationships, with the status and code of their response.
+//
+//nolint:gochecknoglobals
+var batchErrors = []errorMapping{
+	{app.ErrDuplicateBatchKey, http.StatusUnprocessableEntity, "duplicate_key"},
+	{app.ErrUnknownBatchKey, http.StatusUnprocessableEntity, "unknown_key"},
+}
+
+// apiErrors lists every error rejecting a person, a relationship or an item
+// of a batch, with the status and code of their response.
+//
+//nolint:gochecknoglobals
+var apiErrors = append(append(append([]errorMapping{}, personErrors...), relationshipErrors...), batchErrors...)
+
+// errorCode returns the status and code of the response to an error
+// rejecting a person, a relationship or an item of a batch, and whether
+// err was one of them.
+func errorCode(err error) (int, string, bool) {
+	for _, e := range apiErrors {
+		if errors.Is(err, e.err) {
+			return e.status, e.code, true
+		}
+	}
+
+	return 0, "", false
+}
+
+// renderRelationshipError writes the response of an error rejecting
+// a relationship and reports whether err was one of them.
+func renderRelationshipError(w http.ResponseWriter, r *http.Request, err error) bool {
+	for _, e := range relationshipErrors {
+		if errors.Is(err, e.err) {
+			render.Status(r, e.status)
+			render.JSON(w, r, errorResponse{Code: e.code, Message: err.Error()})
+
+			return true
+		}
+	}
+
+	return false
+}
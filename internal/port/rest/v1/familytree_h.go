@@ -5,8 +5,12 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/render"
 	"github.com/newrelic/go-agent/v3/newrelic"
@@ -17,11 +21,28 @@ import (
 func (h *HTTPServer) BuildFamilyTree(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
 
-	t, err := h.application.BuildFamilyTree(r.Context(), id)
+	opts, err := treeOptions(r)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, err.Error())
+
+		return
+	}
+
+	t, err := h.application.BuildFamilyTree(r.Context(), id, opts)
 
 	if errors.Is(err, app.ErrPersonNotFound) {
 		render.Status(r, http.StatusNotFound)
 		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrInvalidTreeMode) || errors.Is(err, app.ErrInvalidGenerations) || errors.Is(err, app.ErrInvalidAsOf) {
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, err.Error())
+
+		return
 	}
 
 	if err != nil {
@@ -35,6 +56,13 @@ func (h *HTTPServer) BuildFamilyTree(w http.ResponseWriter, r *http.Request) {
 	render.Status(r, http.StatusOK)
 
 	switch r.Header.Get("Accept") {
+	case gedcomContentType:
+		people, rs := familyTreeGEDCOM(t)
+		renderGEDCOM(w, r, http.StatusOK, people, rs, nil)
+	case graphvizContentType:
+		renderText(w, http.StatusOK, graphvizContentType, familyTreeDOT(t))
+	case svgContentType:
+		renderText(w, http.StatusOK, svgContentType, familyTreeSVG(t))
 	case "application/xml":
 		render.XML(w, r, t)
 	case "application/octet-stream":
@@ -44,3 +72,198 @@ func (h *HTTPServer) BuildFamilyTree(w http.ResponseWriter, r *http.Request) {
 		render.JSON(w, r, t)
 	}
 }
+
+// legacyTreeSunset is when family trees stop being served from the route of a person.
+const legacyTreeSunset = "Fri, 16 Apr 2027 00:00:00 GMT"
+
+// legacyTreeRequest reports whether a request for a person asks for their
+// family tree instead, as the route of a person used to answer with it,
+// given either its query parameters or one of the formats only trees have.
+func legacyTreeRequest(r *http.Request) bool {
+	q := r.URL.Query()
+	for _, p := range []string{"mode", "generations", "maxDepth"} {
+		if q.Has(p) {
+			return true
+		}
+	}
+
+	switch r.Header.Get("Accept") {
+	case gedcomContentType, graphvizContentType, svgContentType:
+		return true
+	default:
+		return false
+	}
+}
+
+// legacyBuildFamilyTree returns a family tree asked for from the route of
+// a person, pointing at the route of the tree and when this one stops
+// serving it.
+func (h *HTTPServer) legacyBuildFamilyTree(w http.ResponseWriter, r *http.Request) {
+	successor := *r.URL
+	successor.Path = strings.TrimSuffix(successor.Path, "/") + "/tree"
+
+	w.Header().Set("Deprecation", "true")
+	w.Header().Set("Sunset", legacyTreeSunset)
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor.RequestURI()))
+
+	h.BuildFamilyTree(w, r)
+}
+
+// treeOptions reads the family tree options from the query string.
+// The number of generations may be given as either generations or maxDepth,
+// and the moment to build the tree as of in RFC 3339.
+func treeOptions(r *http.Request) (domain.TreeOptions, error) {
+	q := r.URL.Query()
+	opts := domain.TreeOptions{Mode: domain.TreeMode(q.Get("mode"))}
+
+	generations := q.Get("generations")
+	if generations == "" {
+		generations = q.Get("maxDepth")
+	}
+
+	if generations != "" {
+		n, err := strconv.Atoi(generations)
+		if err != nil {
+			return opts, app.ErrInvalidGenerations
+		}
+
+		opts.Generations = n
+	}
+
+	if v := q.Get("asOf"); v != "" {
+		at, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return opts, app.ErrInvalidAsOf
+		}
+
+		opts.AsOf = at
+	}
+
+	return opts, nil
+}
+
+// BaconNumber returns the degree of separation between two people.
+func (h *HTTPServer) BaconNumber(w http.ResponseWriter, r *http.Request) {
+	id1 := chi.URLParam(r, "id1")
+	id2 := chi.URLParam(r, "id2")
+
+	b, err := h.application.BaconNumber(r.Context(), id1, id2)
+
+	if errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrPeopleNotConnected) {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPeopleNotConnected))
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving bacon number from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, b)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(b)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, b)
+	}
+}
+
+// Cousins returns the cousin relationship between two people.
+func (h *HTTPServer) Cousins(w http.ResponseWriter, r *http.Request) {
+	id1 := chi.URLParam(r, "id1")
+	id2 := chi.URLParam(r, "id2")
+
+	c, err := h.application.Cousins(r.Context(), id1, id2)
+
+	if errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrNoSharedAncestor) || errors.Is(err, app.ErrNotCousins) {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.PlainText(w, r, err.Error())
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving cousins from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, c)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(c)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, c)
+	}
+}
+
+// Kinship returns how the first person is related to the second.
+func (h *HTTPServer) Kinship(w http.ResponseWriter, r *http.Request) {
+	id1 := chi.URLParam(r, "id1")
+	id2 := chi.URLParam(r, "id2")
+
+	k, err := h.application.Kinship(r.Context(), id1, id2)
+
+	if errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrPeopleNotConnected) {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPeopleNotConnected))
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving kinship from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, k)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(k)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, k)
+	}
+}
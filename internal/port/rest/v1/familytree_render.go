@@ -0,0 +1,312 @@
+package rest
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+)
+
+const (
+	// graphvizContentType is the media type of Graphviz DOT documents.
+	graphvizContentType = "text/vnd.graphviz"
+	// svgContentType is the media type of SVG images.
+	svgContentType = "image/svg+xml"
+)
+
+// Layout of the SVG rendering, in pixels.
+const (
+	svgMargin    = 20
+	svgBoxHeight = 40
+	svgMinWidth  = 100
+	svgCharWidth = 7
+	svgPadding   = 24
+	svgHGap      = 30
+	svgVGap      = 70
+)
+
+// treeEdge is a link between two members of a family tree.
+// Parent edges other than biological ones carry their type.
+type treeEdge struct {
+	from, to string
+	spouse   bool
+	kind     domain.RelationshipType
+}
+
+// renderText writes a textual body with the given content type.
+func renderText(w http.ResponseWriter, status int, contentType, body string) {
+	w.Header().Set("Content-Type", contentType+"; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(body))
+}
+
+// familyTreeEdges returns the parent and partner links between the members
+// of a family tree, leaving out relatives who are not members themselves.
+func familyTreeEdges(t *domain.FamilyTree) []treeEdge {
+	members := make(map[string]bool, len(t.Members))
+	for _, m := range t.Members {
+		members[m.ID] = true
+	}
+
+	edges := make([]treeEdge, 0)
+	seen := make(map[treeEdge]bool)
+
+	for _, m := range t.Members {
+		for _, fr := range m.Relationships {
+			if !members[fr.ID] {
+				continue
+			}
+
+			var e treeEdge
+
+			switch fr.Relationship {
+			case "parent":
+				e = treeEdge{from: fr.ID, to: m.ID}
+			case "child":
+				e = treeEdge{from: m.ID, to: fr.ID}
+			case "spouse", "partner", "former spouse", "former partner":
+				e = treeEdge{from: m.ID, to: fr.ID, spouse: true}
+				if fr.ID < m.ID {
+					e.from, e.to = fr.ID, m.ID
+				}
+			default:
+				continue
+			}
+
+			if !e.spouse && fr.Type != domain.RelationshipBiological {
+				e.kind = fr.Type
+			}
+
+			if !seen[e] {
+				seen[e] = true
+
+				edges = append(edges, e)
+			}
+		}
+	}
+
+	return edges
+}
+
+// familyTreeGenerations groups the members of a family tree by generation,
+// oldest first, keeping the order they have in the tree.
+func familyTreeGenerations(t *domain.FamilyTree) [][]*domain.Member {
+	byGeneration := make(map[int][]*domain.Member)
+	generations := make([]int, 0)
+
+	for _, m := range t.Members {
+		if _, ok := byGeneration[m.Generation]; !ok {
+			generations = append(generations, m.Generation)
+		}
+
+		byGeneration[m.Generation] = append(byGeneration[m.Generation], m)
+	}
+
+	sort.Ints(generations)
+
+	rows := make([][]*domain.Member, 0, len(generations))
+	for _, g := range generations {
+		rows = append(rows, byGeneration[g])
+	}
+
+	return rows
+}
+
+// familyTreeDOT renders a family tree as a Graphviz DOT document,
+// with each generation on its own rank from top to bottom.
+func familyTreeDOT(t *domain.FamilyTree) string {
+	var b strings.Builder
+
+	b.WriteString("digraph familytree {\n")
+	b.WriteString("\trankdir=TB;\n")
+	b.WriteString("\tnode [shape=box, style=rounded];\n")
+
+	for _, row := range familyTreeGenerations(t) {
+		b.WriteString("\t{ rank=same;")
+
+		for _, m := range row {
+			fmt.Fprintf(&b, " %s [label=%s];", dotQuote(m.ID), dotQuote(m.Name))
+		}
+
+		b.WriteString(" }\n")
+	}
+
+	for _, e := range familyTreeEdges(t) {
+		switch {
+		case e.spouse:
+			fmt.Fprintf(&b, "\t%s -> %s [dir=none, style=dashed, constraint=false];\n", dotQuote(e.from), dotQuote(e.to))
+		case e.kind != "":
+			fmt.Fprintf(&b, "\t%s -> %s [style=dotted, label=%s];\n", dotQuote(e.from), dotQuote(e.to), dotQuote(string(e.kind)))
+		default:
+			fmt.Fprintf(&b, "\t%s -> %s;\n", dotQuote(e.from), dotQuote(e.to))
+		}
+	}
+
+	b.WriteString("}\n")
+
+	return b.String()
+}
+
+// dotQuote returns s as a quoted DOT identifier.
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// familyTreeSVG renders a family tree as an SVG image. Generations are laid
+// out top to bottom, and each row is ordered after the average position of
+// the relatives in the rows around it so that fewer lines cross.
+func familyTreeSVG(t *domain.FamilyTree) string {
+	rows := familyTreeGenerations(t)
+	edges := familyTreeEdges(t)
+
+	parents := make(map[string][]string)
+	children := make(map[string][]string)
+
+	for _, e := range edges {
+		if !e.spouse {
+			parents[e.to] = append(parents[e.to], e.from)
+			children[e.from] = append(children[e.from], e.to)
+		}
+	}
+
+	pos := make(map[string]float64)
+	for _, row := range rows {
+		placeRow(row, pos)
+	}
+
+	for i := 1; i < len(rows); i++ {
+		orderRow(rows[i], parents, pos)
+	}
+
+	for i := len(rows) - 2; i >= 0; i-- {
+		orderRow(rows[i], children, pos)
+	}
+
+	type box struct{ x, y, w float64 }
+
+	boxes := make(map[string]box, len(t.Members))
+	widths := make([]float64, len(rows))
+
+	var width float64
+
+	for i, row := range rows {
+		for j, m := range row {
+			if j > 0 {
+				widths[i] += svgHGap
+			}
+
+			widths[i] += boxWidth(m.Name)
+		}
+
+		if widths[i] > width {
+			width = widths[i]
+		}
+	}
+
+	for i, row := range rows {
+		x := svgMargin + (width-widths[i])/2
+		y := float64(svgMargin + i*(svgBoxHeight+svgVGap))
+
+		for _, m := range row {
+			w := boxWidth(m.Name)
+			boxes[m.ID] = box{x, y, w}
+			x += w + svgHGap
+		}
+	}
+
+	height := float64(2*svgMargin + len(rows)*svgBoxHeight + (len(rows)-1)*svgVGap)
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%.0f" height="%.0f" viewBox="0 0 %.0f %.0f">`+"\n",
+		width+2*svgMargin, height, width+2*svgMargin, height)
+	b.WriteString(`<g fill="none" stroke="#555" stroke-width="1.5">` + "\n")
+
+	for _, e := range edges {
+		from, to := boxes[e.from], boxes[e.to]
+
+		if e.spouse {
+			fmt.Fprintf(&b, `<line x1="%.1f" y1="%.1f" x2="%.1f" y2="%.1f" stroke-dasharray="4 3"/>`+"\n",
+				from.x+from.w/2, from.y+svgBoxHeight/2, to.x+to.w/2, to.y+svgBoxHeight/2)
+
+			continue
+		}
+
+		style := ""
+		if e.kind != "" {
+			style = ` stroke-dasharray="1 3"`
+		}
+
+		x1, y1 := from.x+from.w/2, from.y+svgBoxHeight
+		x2, y2 := to.x+to.w/2, to.y
+		fmt.Fprintf(&b, `<path d="M %.1f %.1f C %.1f %.1f, %.1f %.1f, %.1f %.1f"%s/>`+"\n",
+			x1, y1, x1, (y1+y2)/2, x2, (y1+y2)/2, x2, y2, style)
+	}
+
+	b.WriteString("</g>\n")
+	b.WriteString(`<g font-family="sans-serif" font-size="12" text-anchor="middle">` + "\n")
+
+	for _, row := range rows {
+		for _, m := range row {
+			bx := boxes[m.ID]
+			fmt.Fprintf(&b, `<rect x="%.1f" y="%.1f" width="%.1f" height="%d" rx="6" fill="#fff" stroke="#333"/>`+"\n",
+				bx.x, bx.y, bx.w, svgBoxHeight)
+			fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" dominant-baseline="middle">%s</text>`+"\n",
+				bx.x+bx.w/2, bx.y+svgBoxHeight/2, html.EscapeString(m.Name))
+		}
+	}
+
+	b.WriteString("</g>\n</svg>\n")
+
+	return b.String()
+}
+
+// placeRow records the position of each member of a row,
+// centered around zero so that rows of any size line up.
+func placeRow(row []*domain.Member, pos map[string]float64) {
+	for i, m := range row {
+		pos[m.ID] = float64(i) - float64(len(row)-1)/2
+	}
+}
+
+// orderRow sorts a row by the average position of each member's relatives
+// in the adjacent row, leaving members without any where they are.
+func orderRow(row []*domain.Member, relatives map[string][]string, pos map[string]float64) {
+	keys := make(map[string]float64, len(row))
+
+	for _, m := range row {
+		keys[m.ID] = pos[m.ID]
+
+		var (
+			sum float64
+			n   int
+		)
+
+		for _, r := range relatives[m.ID] {
+			if p, ok := pos[r]; ok {
+				sum += p
+				n++
+			}
+		}
+
+		if n > 0 {
+			keys[m.ID] = sum / float64(n)
+		}
+	}
+
+	sort.SliceStable(row, func(i, j int) bool { return keys[row[i].ID] < keys[row[j].ID] })
+	placeRow(row, pos)
+}
+
+// boxWidth returns the width of the box holding a name.
+func boxWidth(name string) float64 {
+	w := float64(len([]rune(name))*svgCharWidth + svgPadding)
+	if w < svgMinWidth {
+		return svgMinWidth
+	}
+
+	return w
+}
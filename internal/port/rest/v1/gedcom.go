@@ -0,0 +1,323 @@
+package rest
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/bhborges/family-tree-api/internal/domain"
+	"github.com/bhborges/family-tree-api/pkg/gedcom"
+)
+
+// gedcomContentType is the media type of GEDCOM files.
+const gedcomContentType = "text/x-gedcom"
+
+// renderGEDCOM writes people, the parent edges and the partnerships between them as a GEDCOM file.
+func renderGEDCOM(
+	w http.ResponseWriter, r *http.Request, status int,
+	people []*domain.Person, rs []*domain.Relationship, ps []*domain.Partnership,
+) {
+	var b bytes.Buffer
+
+	if err := gedcom.Encode(&b, gedcomRecords(people, rs, ps)); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", gedcomContentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="familytree.ged"`)
+	w.WriteHeader(status)
+	_, _ = w.Write(b.Bytes())
+}
+
+// familyTreeGEDCOM returns the people and parent edges of a family tree.
+func familyTreeGEDCOM(t *domain.FamilyTree) ([]*domain.Person, []*domain.Relationship) {
+	people := make([]*domain.Person, 0, len(t.Members))
+	rs := make([]*domain.Relationship, 0)
+	seen := make(map[[2]string]bool)
+
+	for _, m := range t.Members {
+		people = append(people, &domain.Person{ID: m.ID, Name: m.Name})
+
+		for _, fr := range m.Relationships {
+			var e domain.Relationship
+
+			switch fr.Relationship {
+			case "parent":
+				e = domain.Relationship{ParentID: fr.ID, ChildID: m.ID, Type: fr.Type}
+			case "child":
+				e = domain.Relationship{ParentID: m.ID, ChildID: fr.ID, Type: fr.Type}
+			default:
+				continue
+			}
+
+			if key := [2]string{e.ParentID, e.ChildID}; !seen[key] {
+				seen[key] = true
+
+				rs = append(rs, &e)
+			}
+		}
+	}
+
+	return people, rs
+}
+
+// gedcomRecords maps people to INDI records and groups the children
+// sharing the same parents into FAM records. As a FAM record holds at
+// most two parents, children with more are listed in several families.
+// Parents are listed as HUSB or WIFE after their sex when it is known, and
+// adoptive, step and foster parents are kept in families of their own.
+// Partnerships add MARR and DIV events to the family of the couple.
+func gedcomRecords(
+	people []*domain.Person, rs []*domain.Relationship, partnerships []*domain.Partnership,
+) []*gedcom.Record {
+	indis := make(map[string]*gedcom.Record, len(people))
+	records := []*gedcom.Record{
+		{Tag: "HEAD", Children: []*gedcom.Record{
+			{Tag: "SOUR", Value: "FAMILY-TREE-API"},
+			{Tag: "SUBM", Value: "@U1@"},
+			{Tag: "GEDC", Children: []*gedcom.Record{
+				{Tag: "VERS", Value: "5.5.1"},
+				{Tag: "FORM", Value: "LINEAGE-LINKED"},
+			}},
+			{Tag: "CHAR", Value: "UTF-8"},
+		}},
+		{XRef: "@U1@", Tag: "SUBM", Children: []*gedcom.Record{{Tag: "NAME", Value: "Family Tree API"}}},
+	}
+
+	sexes := make(map[*gedcom.Record]domain.Sex, len(people))
+
+	for i, p := range people {
+		indi := &gedcom.Record{XRef: fmt.Sprintf("@I%d@", i+1), Tag: "INDI", Children: gedcomPerson(p)}
+		indis[p.ID] = indi
+		sexes[indi] = p.Sex
+		records = append(records, indi)
+	}
+
+	type lineage struct {
+		child string
+		kind  domain.RelationshipType
+	}
+
+	parents := make(map[lineage][]*gedcom.Record)
+	children := make([]lineage, 0)
+
+	for _, r := range rs {
+		if indis[r.ParentID] == nil || indis[r.ChildID] == nil {
+			continue
+		}
+
+		l := lineage{r.ChildID, r.Type}
+		if l.kind == "" {
+			l.kind = domain.RelationshipBiological
+		}
+
+		if _, ok := parents[l]; !ok {
+			children = append(children, l)
+		}
+
+		parents[l] = append(parents[l], indis[r.ParentID])
+	}
+
+	fams := make(map[string]*gedcom.Record)
+	famList := make([]*gedcom.Record, 0)
+
+	// family returns the FAM record of a couple, or of a single parent,
+	// creating it the first time the couple is seen.
+	family := func(kind domain.RelationshipType, couple []*gedcom.Record) *gedcom.Record {
+		key := string(kind) + couple[0].XRef
+		if len(couple) > 1 {
+			key += couple[1].XRef
+		}
+
+		if fam, ok := fams[key]; ok {
+			return fam
+		}
+
+		fam := &gedcom.Record{XRef: fmt.Sprintf("@F%d@", len(famList)+1), Tag: "FAM"}
+		fams[key] = fam
+		famList = append(famList, fam)
+
+		husband, wife := couple[0], (*gedcom.Record)(nil)
+		if len(couple) > 1 {
+			wife = couple[1]
+		}
+
+		if sexes[husband] == domain.SexFemale || (wife != nil && sexes[wife] == domain.SexMale) {
+			husband, wife = wife, husband
+		}
+
+		for _, spouse := range []struct {
+			tag string
+			rec *gedcom.Record
+		}{{"HUSB", husband}, {"WIFE", wife}} {
+			if spouse.rec == nil {
+				continue
+			}
+
+			fam.Children = append(fam.Children, &gedcom.Record{Tag: spouse.tag, Value: spouse.rec.XRef})
+			spouse.rec.Children = append(spouse.rec.Children, &gedcom.Record{Tag: "FAMS", Value: fam.XRef})
+		}
+
+		return fam
+	}
+
+	for _, l := range children {
+		c := l.child
+		ps := parents[l]
+		sort.Slice(ps, func(i, j int) bool { return xrefLess(ps[i].XRef, ps[j].XRef) })
+
+		for i := 0; i < len(ps); i += 2 {
+			end := i + 2
+			if end > len(ps) {
+				end = len(ps)
+			}
+
+			fam := family(l.kind, ps[i:end])
+
+			famc := &gedcom.Record{Tag: "FAMC", Value: fam.XRef}
+			if pedi, ok := gedcomPedigrees[l.kind]; ok {
+				famc.Children = []*gedcom.Record{{Tag: "PEDI", Value: pedi}}
+			}
+
+			fam.Children = append(fam.Children, &gedcom.Record{Tag: "CHIL", Value: indis[c].XRef})
+			indis[c].Children = append(indis[c].Children, famc)
+		}
+	}
+
+	for _, p := range partnerships {
+		p1, p2 := indis[p.Person1ID], indis[p.Person2ID]
+		if p1 == nil || p2 == nil {
+			continue
+		}
+
+		couple := []*gedcom.Record{p1, p2}
+		if xrefLess(p2.XRef, p1.XRef) {
+			couple = []*gedcom.Record{p2, p1}
+		}
+
+		fam := family(domain.RelationshipBiological, couple)
+		fam.Children = append(fam.Children, gedcomPartnership(p)...)
+	}
+
+	records = append(records, famList...)
+
+	return append(records, &gedcom.Record{Tag: "TRLR"})
+}
+
+// gedcomSexes maps the sex of a person to the value of the SEX tag.
+//
+//nolint:gochecknoglobals
+var gedcomSexes = map[domain.Sex]string{
+	domain.SexMale: "M", domain.SexFemale: "F", domain.SexOther: "X", domain.SexUnknown: "U",
+}
+
+// gedcomPedigrees maps relationship types to the value of the PEDI tag.
+// GEDCOM has no pedigree for step children, who are left without one.
+//
+//nolint:gochecknoglobals
+var gedcomPedigrees = map[domain.RelationshipType]string{
+	domain.RelationshipBiological: "birth", domain.RelationshipAdoptive: "adopted", domain.RelationshipFoster: "foster",
+}
+
+// gedcomPerson returns the records describing a person within their INDI
+// record: their names, sex, and the date and place of their birth and death.
+func gedcomPerson(p *domain.Person) []*gedcom.Record {
+	name := &gedcom.Record{Tag: "NAME", Value: p.Name}
+
+	if p.GivenName != "" || p.Surname != "" {
+		name.Value = strings.TrimSpace(p.GivenName + " /" + p.Surname + "/")
+
+		if p.GivenName != "" {
+			name.Children = append(name.Children, &gedcom.Record{Tag: "GIVN", Value: p.GivenName})
+		}
+
+		if p.Surname != "" {
+			name.Children = append(name.Children, &gedcom.Record{Tag: "SURN", Value: p.Surname})
+		}
+	}
+
+	rs := []*gedcom.Record{name}
+
+	if p.MaidenName != "" {
+		rs = append(rs, &gedcom.Record{Tag: "NAME", Value: strings.TrimSpace(p.GivenName + " /" + p.MaidenName + "/"),
+			Children: []*gedcom.Record{{Tag: "TYPE", Value: "maiden"}}})
+	}
+
+	if sex, ok := gedcomSexes[p.Sex]; ok {
+		rs = append(rs, &gedcom.Record{Tag: "SEX", Value: sex})
+	}
+
+	if ev := gedcomEvent("BIRT", p.BirthDate, p.BirthPlace); ev != nil {
+		rs = append(rs, ev)
+	}
+
+	if ev := gedcomEvent("DEAT", p.DeathDate, p.DeathPlace); ev != nil {
+		rs = append(rs, ev)
+	}
+
+	return rs
+}
+
+// gedcomPartnership returns the events of a partnership within the FAM
+// record of the couple. Partnerships other than marriages are written as
+// a MARR event with a TYPE, as GEDCOM has no event of their own.
+func gedcomPartnership(p *domain.Partnership) []*gedcom.Record {
+	marr := gedcomEvent("MARR", p.StartDate, "")
+	if marr == nil {
+		marr = &gedcom.Record{Tag: "MARR", Value: "Y"}
+	}
+
+	switch p.Type {
+	case domain.PartnershipCivilUnion:
+		marr.Children = append(marr.Children, &gedcom.Record{Tag: "TYPE", Value: "civil union"})
+	case domain.PartnershipPartnership:
+		marr.Children = append(marr.Children, &gedcom.Record{Tag: "TYPE", Value: "partnership"})
+	case domain.PartnershipMarriage:
+	}
+
+	rs := []*gedcom.Record{marr}
+
+	if p.EndReason == domain.EndReasonDivorce {
+		div := gedcomEvent("DIV", p.EndDate, "")
+		if div == nil {
+			div = &gedcom.Record{Tag: "DIV", Value: "Y"}
+		}
+
+		rs = append(rs, div)
+	}
+
+	return rs
+}
+
+// gedcomEvent returns an event record such as BIRT,
+// or nil when neither its date nor its place is known.
+func gedcomEvent(tag string, date *domain.PartialDate, place string) *gedcom.Record {
+	if date == nil && place == "" {
+		return nil
+	}
+
+	ev := &gedcom.Record{Tag: tag}
+
+	if date != nil {
+		ev.Children = append(ev.Children, &gedcom.Record{Tag: "DATE", Value: date.GEDCOM()})
+	}
+
+	if place != "" {
+		ev.Children = append(ev.Children, &gedcom.Record{Tag: "PLAC", Value: place})
+	}
+
+	return ev
+}
+
+// xrefLess orders cross-reference identifiers by their number.
+func xrefLess(a, b string) bool {
+	if len(a) != len(b) {
+		return len(a) < len(b)
+	}
+
+	return strings.Compare(a, b) < 0
+}
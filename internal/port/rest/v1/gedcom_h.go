@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+	"github.com/go-chi/render"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// maxImportSize is the largest GEDCOM file accepted, in bytes.
+const maxImportSize = 32 << 20
+
+// ImportGEDCOM creates people and relationships from a GEDCOM file sent as the request body.
+func (h *HTTPServer) ImportGEDCOM(w http.ResponseWriter, r *http.Request) {
+	body := http.MaxBytesReader(w, r.Body, maxImportSize)
+
+	report, err := h.application.ImportGEDCOM(r.Context(), body)
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		render.Status(r, http.StatusRequestEntityTooLarge)
+		render.PlainText(w, r, fmt.Sprintf("GEDCOM file larger than %d bytes", tooLarge.Limit))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrInvalidGEDCOM) {
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, err.Error())
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error importing GEDCOM from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.JSON(w, r, report)
+}
+
+// ExportGEDCOM returns every person, relationship and partnership as a GEDCOM file.
+func (h *HTTPServer) ExportGEDCOM(w http.ResponseWriter, r *http.Request) {
+	page, err := h.application.ListPeople(r.Context(), domain.PeopleQuery{})
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving list of peoples from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	rs, err := h.application.ListRelationships(r.Context(), domain.RelationshipFilter{})
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving list of relatioships from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	ps, err := h.application.ListPartnerships(r.Context(), "")
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving list of partnerships from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	renderGEDCOM(w, r, http.StatusOK, page.People, rs, ps)
+}
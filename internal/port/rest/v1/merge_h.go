@@ -0,0 +1,182 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// mergeRequest names the duplicate to merge into a person.
+type mergeRequest struct {
+	Duplicate string `json:"duplicate"`
+}
+
+// ListDuplicateCandidates returns the pairs of people that may be the same
+// person, optionally only those including the person query parameter and
+// scoring at least minScore, the likeliest first.
+func (h *HTTPServer) ListDuplicateCandidates(w http.ResponseWriter, r *http.Request) {
+	q, err := duplicateQuery(r)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, err.Error())
+
+		return
+	}
+
+	cs, err := h.application.ListDuplicateCandidates(r.Context(), q)
+
+	if errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	for _, e := range []error{app.ErrInvalidScore, app.ErrInvalidLimit} {
+		if errors.Is(err, e) {
+			render.Status(r, http.StatusBadRequest)
+			render.PlainText(w, r, fmt.Sprintf("%s", e))
+
+			return
+		}
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving duplicate candidates from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, cs)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(cs)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, cs)
+	}
+}
+
+// duplicateQuery reads the duplicate candidates asked for from the query string.
+func duplicateQuery(r *http.Request) (domain.DuplicateQuery, error) {
+	v := r.URL.Query()
+	q := domain.DuplicateQuery{PersonID: v.Get("person")}
+
+	if minScore := v.Get("minScore"); minScore != "" {
+		f, err := strconv.ParseFloat(minScore, 64)
+		if err != nil {
+			return q, app.ErrInvalidScore
+		}
+
+		q.MinScore = f
+	}
+
+	if limit := v.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return q, app.ErrInvalidLimit
+		}
+
+		q.Limit = n
+	}
+
+	return q, nil
+}
+
+// MergePerson merges the duplicate named in the body into the person,
+// returning the record of the merge.
+func (h *HTTPServer) MergePerson(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+	mr := mergeRequest{}
+
+	if err := json.NewDecoder(r.Body).Decode(&mr); err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error decoding data", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	m, err := h.application.MergePeople(r.Context(), id, mr.Duplicate)
+
+	if errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrInvalidMerge) {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrInvalidMerge))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrTooManyParents) {
+		render.Status(r, http.StatusConflict)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrTooManyParents))
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error merging people from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, m)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(m)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, m)
+	}
+}
+
+// ListPersonMerges returns the merges the person query parameter took part
+// in, or every merge when it is left out, the latest first.
+func (h *HTTPServer) ListPersonMerges(w http.ResponseWriter, r *http.Request) {
+	ms, err := h.application.ListPersonMerges(r.Context(), r.URL.Query().Get("person"))
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving merges from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, ms)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(ms)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, ms)
+	}
+}
@@ -0,0 +1,163 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// ListPartnerships list all partnerships, or only those
+// of the person given by the person query parameter.
+func (h *HTTPServer) ListPartnerships(w http.ResponseWriter, r *http.Request) {
+	ps, err := h.application.ListPartnerships(r.Context(), r.URL.Query().Get("person"))
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving list of partnerships from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, ps)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(ps)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, ps)
+	}
+}
+
+// GetPartnershipByID returns a partnership.
+func (h *HTTPServer) GetPartnershipByID(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	p, err := h.application.GetPartnershipByID(r.Context(), id)
+
+	if errors.Is(err, app.ErrPartnershipNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPartnershipNotFound))
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving partnership from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+	render.JSON(w, r, p)
+}
+
+// CreatePartnership create a new partnership.
+func (h *HTTPServer) CreatePartnership(w http.ResponseWriter, r *http.Request) {
+	dp := domain.Partnership{}
+
+	if err := json.NewDecoder(r.Body).Decode(&dp); err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error decoding data", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	id, err := h.application.CreatePartnership(r.Context(), dp)
+
+	if errors.Is(err, app.ErrInvalidPartnership) || errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.PlainText(w, r, err.Error())
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error creating partnership from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusCreated)
+	render.PlainText(w, r, id)
+}
+
+// UpdatePartnership replaces an existing partnership.
+func (h *HTTPServer) UpdatePartnership(w http.ResponseWriter, r *http.Request) {
+	dp := domain.Partnership{}
+
+	if err := json.NewDecoder(r.Body).Decode(&dp); err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error decoding data", zap.Error(err))
+		w.WriteHeader(http.StatusBadRequest)
+
+		return
+	}
+
+	dp.ID = chi.URLParam(r, "id")
+
+	err := h.application.UpdatePartnership(r.Context(), dp)
+
+	if errors.Is(err, app.ErrPartnershipNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPartnershipNotFound))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrInvalidPartnership) || errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.PlainText(w, r, err.Error())
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error updating partnership from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// DeletePartnership deletes a partnership.
+func (h *HTTPServer) DeletePartnership(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	err := h.application.DeletePartnership(r.Context(), id)
+
+	if errors.Is(err, app.ErrPartnershipNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPartnershipNotFound))
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error deleting partnership from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
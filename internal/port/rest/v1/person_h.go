@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 
 	"github.com/bhborges/family-tree-api/internal/app"
 	"github.com/bhborges/family-tree-api/internal/domain"
@@ -14,13 +16,29 @@ import (
 	"go.uber.org/zap"
 )
 
-// ListPeople returns a list of people.
+// defaultPeopleLimit is the number of people listed in a page when no limit is given.
+const defaultPeopleLimit = 50
+
+// ListPeople returns a page of people. When more people follow, the cursor to
+// the next page is given in the X-Next-Cursor header and its URL in Link.
 func (h *HTTPServer) ListPeople(w http.ResponseWriter, r *http.Request) {
-	p, err := h.application.ListPeople(r.Context())
+	q, err := peopleQuery(r)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, err.Error())
 
-	if errors.Is(err, app.ErrPersonNotFound) {
-		render.Status(r, http.StatusNotFound)
-		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+		return
+	}
+
+	page, err := h.application.ListPeople(r.Context(), q)
+
+	for _, e := range []error{app.ErrInvalidSort, app.ErrInvalidLimit, domain.ErrInvalidCursor} {
+		if errors.Is(err, e) {
+			render.Status(r, http.StatusBadRequest)
+			render.PlainText(w, r, fmt.Sprintf("%s", e))
+
+			return
+		}
 	}
 
 	if err != nil {
@@ -31,6 +49,18 @@ func (h *HTTPServer) ListPeople(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if page.NextCursor != "" {
+		next := *r.URL
+		values := next.Query()
+		values.Set("cursor", page.NextCursor)
+		next.RawQuery = values.Encode()
+
+		w.Header().Set("X-Next-Cursor", page.NextCursor)
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+
+	p := page.People
+
 	render.Status(r, http.StatusOK)
 
 	switch r.Header.Get("Accept") {
@@ -44,15 +74,41 @@ func (h *HTTPServer) ListPeople(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// GetPersonByID returns a person.
+// GetPersonByID returns a person, along with the relatives named in the
+// comma-separated include query parameter: parents, children, siblings or
+// spouse. Requests for a family tree, given its query parameters or formats,
+// are still answered with the tree, flagged as deprecated.
 func (h *HTTPServer) GetPersonByID(w http.ResponseWriter, r *http.Request) {
+	if legacyTreeRequest(r) {
+		h.legacyBuildFamilyTree(w, r)
+
+		return
+	}
+
 	id := chi.URLParam(r, "id")
 
-	p, err := h.application.GetPersonByID(r.Context(), id)
+	var include []domain.PersonInclude
+
+	if v := r.URL.Query().Get("include"); v != "" {
+		for _, inc := range strings.Split(v, ",") {
+			include = append(include, domain.PersonInclude(strings.TrimSpace(inc)))
+		}
+	}
+
+	p, err := h.application.GetPersonByID(r.Context(), id, include...)
 
 	if errors.Is(err, app.ErrPersonNotFound) {
 		render.Status(r, http.StatusNotFound)
 		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrInvalidInclude) {
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrInvalidInclude))
+
+		return
 	}
 
 	if err != nil {
@@ -64,7 +120,74 @@ func (h *HTTPServer) GetPersonByID(w http.ResponseWriter, r *http.Request) {
 	}
 
 	render.Status(r, http.StatusOK)
-	render.JSON(w, r, p)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, p)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(p)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, p)
+	}
+}
+
+// peopleQuery reads the page of people asked for from the query string.
+func peopleQuery(r *http.Request) (domain.PeopleQuery, error) {
+	v := r.URL.Query()
+	q := domain.PeopleQuery{
+		Name:    v.Get("name"),
+		Surname: v.Get("surname"),
+		Sort:    domain.PeopleSort(v.Get("sort")),
+		Limit:   defaultPeopleLimit,
+	}
+
+	if limit := v.Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return q, app.ErrInvalidLimit
+		}
+
+		q.Limit = n
+	}
+
+	if cursor := v.Get("cursor"); cursor != "" {
+		c, err := domain.ParsePeopleCursor(cursor)
+		if err != nil {
+			return q, err
+		}
+
+		q.After = &c
+	}
+
+	return q, nil
+}
+
+// decodePersonUpdate reads a partial update of a person from the request body.
+// Fields given as null or as an empty string are listed as cleared instead.
+func decodePersonUpdate(r *http.Request) (domain.Person, error) {
+	p := domain.Person{}
+	fields := map[string]json.RawMessage{}
+
+	if err := json.NewDecoder(r.Body).Decode(&fields); err != nil {
+		return p, err
+	}
+
+	for field, value := range fields {
+		if v := string(value); v == "null" || v == `""` {
+			p.Clear = append(p.Clear, field)
+			delete(fields, field)
+		}
+	}
+
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return p, err
+	}
+
+	err = json.Unmarshal(b, &p)
+
+	return p, err
 }
 
 // CreatePerson create a new person.
@@ -80,6 +203,14 @@ func (h *HTTPServer) CreatePerson(w http.ResponseWriter, r *http.Request) {
 	}
 
 	id, err := h.application.CreatePerson(r.Context(), p)
+
+	if errors.Is(err, app.ErrInvalidSex) {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrInvalidSex))
+
+		return
+	}
+
 	if err != nil {
 		newrelic.FromContext(r.Context()).NoticeError(err)
 		h.log.Error("unexpected error creating person from API", zap.Error(err))
@@ -92,8 +223,14 @@ func (h *HTTPServer) CreatePerson(w http.ResponseWriter, r *http.Request) {
 	render.PlainText(w, r, id)
 }
 
-// CreatePeople creates a new batch of people.
+// CreatePeople creates a new batch of people, all of them or none
+// unless the mode query parameter asks for best effort.
 func (h *HTTPServer) CreatePeople(w http.ResponseWriter, r *http.Request) {
+	best, ok := bestEffort(w, r)
+	if !ok {
+		return
+	}
+
 	var people []domain.Person
 
 	if err := json.NewDecoder(r.Body).Decode(&people); err != nil {
@@ -104,19 +241,24 @@ func (h *HTTPServer) CreatePeople(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ids := make([]string, 0, len(people))
+	if best {
+		h.renderBatchResults(w, r, h.application.CreatePeopleBestEffort(r.Context(), people))
 
-	for _, p := range people {
-		id, err := h.application.CreatePerson(r.Context(), p)
-		if err != nil {
-			newrelic.FromContext(r.Context()).NoticeError(err)
-			h.log.Error("unexpected error creating person from API", zap.Error(err))
-			w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-			return
-		}
+	ids, err := h.application.CreatePeople(r.Context(), people)
+
+	if renderBatchError(w, r, err) {
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error creating people from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
 
-		ids = append(ids, id)
+		return
 	}
 
 	render.Status(r, http.StatusCreated)
@@ -125,9 +267,8 @@ func (h *HTTPServer) CreatePeople(w http.ResponseWriter, r *http.Request) {
 
 // UpdatePerson update a person.
 func (h *HTTPServer) UpdatePerson(w http.ResponseWriter, r *http.Request) {
-	p := domain.Person{}
-
-	if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+	p, err := decodePersonUpdate(r)
+	if err != nil {
 		newrelic.FromContext(r.Context()).NoticeError(err)
 		h.log.Error("unexpected error decoding data", zap.Error(err))
 		w.WriteHeader(http.StatusBadRequest)
@@ -135,7 +276,22 @@ func (h *HTTPServer) UpdatePerson(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := h.application.UpdatePerson(r.Context(), p)
+	err = h.application.UpdatePerson(r.Context(), p)
+
+	if errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrInvalidSex) {
+		render.Status(r, http.StatusUnprocessableEntity)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrInvalidSex))
+
+		return
+	}
+
 	if err != nil {
 		newrelic.FromContext(r.Context()).NoticeError(err)
 		h.log.Error("unexpected error updating person from API", zap.Error(err))
@@ -147,15 +303,49 @@ func (h *HTTPServer) UpdatePerson(w http.ResponseWriter, r *http.Request) {
 	render.Status(r, http.StatusOK)
 }
 
-// DeletePerson delete a person.
+// DeletePerson deletes a person under the policy query parameter, restrict,
+// detach or cascade, returning what was deleted along with them. When the
+// dryRun query parameter is true nothing is deleted and the response tells
+// what would be.
 func (h *HTTPServer) DeletePerson(w http.ResponseWriter, r *http.Request) {
 	id := chi.URLParam(r, "id")
+	q := r.URL.Query()
+
+	dryRun := false
 
-	err := h.application.DeletePerson(r.Context(), id)
+	if v := q.Get("dryRun"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			render.Status(r, http.StatusBadRequest)
+			render.PlainText(w, r, "invalid dryRun")
+
+			return
+		}
+
+		dryRun = b
+	}
+
+	report, err := h.application.DeletePerson(r.Context(), id, domain.DeletePolicy(q.Get("policy")), dryRun)
 
 	if errors.Is(err, app.ErrPersonNotFound) {
 		render.Status(r, http.StatusNotFound)
 		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrInvalidDeletePolicy) {
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrInvalidDeletePolicy))
+
+		return
+	}
+
+	if errors.Is(err, app.ErrPersonHasRelatives) {
+		render.Status(r, http.StatusConflict)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonHasRelatives))
+
+		return
 	}
 
 	if err != nil {
@@ -167,4 +357,14 @@ func (h *HTTPServer) DeletePerson(w http.ResponseWriter, r *http.Request) {
 	}
 
 	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, report)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(report)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, report)
+	}
 }
@@ -14,9 +14,24 @@ import (
 	"go.uber.org/zap"
 )
 
-// ListRelationships list all relationships
+// ListRelationships list all relationships, optionally filtered
+// by the type, parent and child query parameters.
 func (h *HTTPServer) ListRelationships(w http.ResponseWriter, r *http.Request) {
-	p, err := h.application.ListRelationships(r.Context())
+	q := r.URL.Query()
+	f := domain.RelationshipFilter{
+		Type:     domain.RelationshipType(q.Get("type")),
+		ParentID: q.Get("parent"),
+		ChildID:  q.Get("child"),
+	}
+
+	p, err := h.application.ListRelationships(r.Context(), f)
+
+	if errors.Is(err, app.ErrInvalidRelationshipType) {
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrInvalidRelationshipType))
+
+		return
+	}
 
 	if errors.Is(err, app.ErrRelationshipNotFound) {
 		render.Status(r, http.StatusNotFound)
@@ -57,7 +72,13 @@ func (h *HTTPServer) UpdateRelationship(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	dr.ID = chi.URLParam(r, "id")
+
 	if err := h.application.UpdateRelationship(r.Context(), dr); err != nil {
+		if renderRelationshipError(w, r, err) {
+			return
+		}
+
 		newrelic.FromContext(r.Context()).NoticeError(err)
 		h.log.Error("unexpected error updating relationship from API", zap.Error(err))
 
@@ -106,9 +127,8 @@ func (h *HTTPServer) CreateRelationship(w http.ResponseWriter, r *http.Request)
 
 	id, err := h.application.CreateRelationship(r.Context(), dr)
 
-	if errors.Is(err, app.ErrIncestuousOffspring) {
-		render.Status(r, http.StatusUnprocessableEntity)
-		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrIncestuousOffspring))
+	if renderRelationshipError(w, r, err) {
+		return
 	}
 
 	if err != nil {
@@ -123,8 +143,14 @@ func (h *HTTPServer) CreateRelationship(w http.ResponseWriter, r *http.Request)
 	render.PlainText(w, r, id)
 }
 
-// CreateRelationships creates a new batch of relationships.
+// CreateRelationships creates a new batch of relationships, all of them
+// or none unless the mode query parameter asks for best effort.
 func (h *HTTPServer) CreateRelationships(w http.ResponseWriter, r *http.Request) {
+	best, ok := bestEffort(w, r)
+	if !ok {
+		return
+	}
+
 	drs := []domain.Relationship{}
 
 	if err := json.NewDecoder(r.Body).Decode(&drs); err != nil {
@@ -135,10 +161,16 @@ func (h *HTTPServer) CreateRelationships(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if best {
+		h.renderBatchResults(w, r, h.application.CreateRelationshipsBestEffort(r.Context(), drs))
+
+		return
+	}
+
 	ids, err := h.application.CreateRelationships(r.Context(), drs)
-	if errors.Is(err, app.ErrIncestuousOffspring) {
-		render.Status(r, http.StatusUnprocessableEntity)
-		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrIncestuousOffspring))
+
+	if renderBatchError(w, r, err) {
+		return
 	}
 
 	if err != nil {
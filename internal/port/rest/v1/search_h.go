@@ -0,0 +1,86 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+	"github.com/bhborges/family-tree-api/internal/domain"
+
+	"github.com/go-chi/render"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// SearchPeople returns the people whose names match the q query parameter,
+// the best match first, optionally born between bornFrom and bornTo.
+func (h *HTTPServer) SearchPeople(w http.ResponseWriter, r *http.Request) {
+	s, err := personSearch(r)
+	if err != nil {
+		render.Status(r, http.StatusBadRequest)
+		render.PlainText(w, r, err.Error())
+
+		return
+	}
+
+	rs, err := h.application.SearchPeople(r.Context(), s)
+
+	for _, e := range []error{app.ErrInvalidSearch, app.ErrInvalidLimit} {
+		if errors.Is(err, e) {
+			render.Status(r, http.StatusBadRequest)
+			render.PlainText(w, r, fmt.Sprintf("%s", e))
+
+			return
+		}
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error searching people from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, rs)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(rs)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, rs)
+	}
+}
+
+// personSearch reads a search from the query string.
+func personSearch(r *http.Request) (domain.PersonSearch, error) {
+	v := r.URL.Query()
+	s := domain.PersonSearch{Query: v.Get("q")}
+
+	for _, p := range []struct {
+		name string
+		dst  *int
+		err  error
+	}{
+		{"bornFrom", &s.BornFrom, app.ErrInvalidSearch},
+		{"bornTo", &s.BornTo, app.ErrInvalidSearch},
+		{"limit", &s.Limit, app.ErrInvalidLimit},
+	} {
+		if value := v.Get(p.name); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || (p.name == "limit" && n < 1) {
+				return s, p.err
+			}
+
+			*p.dst = n
+		}
+	}
+
+	return s, nil
+}
@@ -3,6 +3,7 @@ package rest
 
 import (
 	"context"
+	"io"
 
 	"github.com/bhborges/family-tree-api/internal/domain"
 	"github.com/bhborges/family-tree-api/pkg/http"
@@ -23,18 +24,40 @@ type HTTPServer struct {
 
 // Application specifies the signature of Application.
 type Application interface {
-	ListPeople(context.Context) ([]*domain.Person, error)
-	GetPersonByID(context.Context, string) (*domain.Person, error)
+	ListPeople(context.Context, domain.PeopleQuery) (*domain.PeoplePage, error)
+	GetPersonByID(context.Context, string, ...domain.PersonInclude) (*domain.Person, error)
+	SearchPeople(context.Context, domain.PersonSearch) ([]*domain.SearchResult, error)
 	CreatePerson(context.Context, domain.Person) (string, error)
 	CreatePeople(context.Context, []domain.Person) ([]string, error)
+	CreatePeopleBestEffort(context.Context, []domain.Person) []domain.BatchResult
 	UpdatePerson(context.Context, domain.Person) error
-	DeletePerson(context.Context, string) error
-	ListRelationships(context.Context) ([]*domain.Relationship, error)
+	DeletePerson(context.Context, string, domain.DeletePolicy, bool) (*domain.DeleteReport, error)
+	ListRelationships(context.Context, domain.RelationshipFilter) ([]*domain.Relationship, error)
 	CreateRelationship(context.Context, domain.Relationship) (string, error)
 	CreateRelationships(context.Context, []domain.Relationship) ([]string, error)
+	CreateRelationshipsBestEffort(context.Context, []domain.Relationship) []domain.BatchResult
 	UpdateRelationship(context.Context, domain.Relationship) error
 	DeleteRelationship(context.Context, string) error
-	BuildFamilyTree(context.Context, string) (*domain.FamilyTree, error)
+	CreateFamilyBatch(context.Context, domain.FamilyBatch) (*domain.FamilyBatchResult, error)
+	ListPartnerships(context.Context, string) ([]*domain.Partnership, error)
+	GetPartnershipByID(context.Context, string) (*domain.Partnership, error)
+	CreatePartnership(context.Context, domain.Partnership) (string, error)
+	UpdatePartnership(context.Context, domain.Partnership) error
+	DeletePartnership(context.Context, string) error
+	BuildFamilyTree(context.Context, string, domain.TreeOptions) (*domain.FamilyTree, error)
+	BaconNumber(context.Context, string, string) (*domain.BaconNumber, error)
+	Cousins(context.Context, string, string) (*domain.Cousins, error)
+	Kinship(context.Context, string, string) (*domain.Kinship, error)
+	ImportGEDCOM(context.Context, io.Reader) (*domain.ImportReport, error)
+	ListDuplicateCandidates(context.Context, domain.DuplicateQuery) ([]*domain.DuplicateCandidate, error)
+	MergePeople(context.Context, string, string) (*domain.PersonMerge, error)
+	ListPersonMerges(context.Context, string) ([]*domain.PersonMerge, error)
+	PersonHistory(context.Context, string) ([]*domain.AuditEntry, error)
+	ListTrash(context.Context) (*domain.Trash, error)
+	RestorePerson(context.Context, string) (*domain.DeleteReport, error)
+	PurgePerson(context.Context, string) error
+	RestoreRelationship(context.Context, string) error
+	PurgeRelationship(context.Context, string) error
 }
 
 // ProvideHTTPServer returns a new instance of an HTTP server.
@@ -56,11 +79,16 @@ func RegisterHandlers(h *HTTPServer) {
 	h.router.Route("/familytree", func(r chi.Router) {
 		r.Use(http.FormatMiddleware)
 		r.Use(http.SetContentTypeMiddleware)
+		r.Use(auditorMiddleware)
 		r.Route("/person", func(r chi.Router) {
 			r.Get("/", http.WithAPM(h.apm, "/", h.ListPeople))
-			r.Get("/{id}", http.WithAPM(h.apm, "/{id}", h.BuildFamilyTree))
+			r.Get("/search", http.WithAPM(h.apm, "/search", h.SearchPeople))
+			r.Get("/{id}", http.WithAPM(h.apm, "/{id}", h.GetPersonByID))
+			r.Get("/{id}/tree", http.WithAPM(h.apm, "/{id}/tree", h.BuildFamilyTree))
+			r.Get("/{id}/history", http.WithAPM(h.apm, "/{id}/history", h.PersonHistory))
 			r.Post("/", http.WithAPM(h.apm, "/", h.CreatePerson))
 			r.Patch("/", http.WithAPM(h.apm, "/", h.UpdatePerson))
+			r.Post("/{id}/merge", http.WithAPM(h.apm, "/{id}/merge", h.MergePerson))
 			r.Delete("/{id}", http.WithAPM(h.apm, "/{id}", h.DeletePerson))
 		})
 		r.Route("/people", func(r chi.Router) {
@@ -75,5 +103,45 @@ func RegisterHandlers(h *HTTPServer) {
 			r.Get("/", http.WithAPM(h.apm, "/", h.ListRelationships))
 			r.Post("/", http.WithAPM(h.apm, "/", h.CreateRelationships))
 		})
+		r.Route("/batch", func(r chi.Router) {
+			r.Post("/", http.WithAPM(h.apm, "/", h.CreateFamilyBatch))
+		})
+		r.Route("/duplicates", func(r chi.Router) {
+			r.Get("/", http.WithAPM(h.apm, "/", h.ListDuplicateCandidates))
+		})
+		r.Route("/merges", func(r chi.Router) {
+			r.Get("/", http.WithAPM(h.apm, "/", h.ListPersonMerges))
+		})
+		r.Route("/trash", func(r chi.Router) {
+			r.Get("/", http.WithAPM(h.apm, "/", h.ListTrash))
+			r.Post("/person/{id}/restore", http.WithAPM(h.apm, "/person/{id}/restore", h.RestorePerson))
+			r.Delete("/person/{id}", http.WithAPM(h.apm, "/person/{id}", h.PurgePerson))
+			r.Post("/relationship/{id}/restore", http.WithAPM(h.apm, "/relationship/{id}/restore", h.RestoreRelationship))
+			r.Delete("/relationship/{id}", http.WithAPM(h.apm, "/relationship/{id}", h.PurgeRelationship))
+		})
+		r.Route("/partnership", func(r chi.Router) {
+			r.Post("/", http.WithAPM(h.apm, "/", h.CreatePartnership))
+			r.Get("/{id}", http.WithAPM(h.apm, "/{id}", h.GetPartnershipByID))
+			r.Put("/{id}", http.WithAPM(h.apm, "/{id}", h.UpdatePartnership))
+			r.Delete("/{id}", http.WithAPM(h.apm, "/{id}", h.DeletePartnership))
+		})
+		r.Route("/partnerships", func(r chi.Router) {
+			r.Get("/", http.WithAPM(h.apm, "/", h.ListPartnerships))
+		})
+		r.Route("/bacon", func(r chi.Router) {
+			r.Get("/{id1}/{id2}", http.WithAPM(h.apm, "/{id1}/{id2}", h.BaconNumber))
+		})
+		r.Route("/cousins", func(r chi.Router) {
+			r.Get("/{id1}/{id2}", http.WithAPM(h.apm, "/{id1}/{id2}", h.Cousins))
+		})
+		r.Route("/kinship", func(r chi.Router) {
+			r.Get("/{id1}/{id2}", http.WithAPM(h.apm, "/{id1}/{id2}", h.Kinship))
+		})
+		r.Route("/import", func(r chi.Router) {
+			r.Post("/gedcom", http.WithAPM(h.apm, "/gedcom", h.ImportGEDCOM))
+		})
+		r.Route("/export", func(r chi.Router) {
+			r.Get("/gedcom", http.WithAPM(h.apm, "/gedcom", h.ExportGEDCOM))
+		})
 	})
 }
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/bhborges/family-tree-api/internal/app"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	"github.com/newrelic/go-agent/v3/newrelic"
+	"go.uber.org/zap"
+)
+
+// ListTrash returns the people deleted, and the relationships deleted
+// on their own, the latest deleted first.
+func (h *HTTPServer) ListTrash(w http.ResponseWriter, r *http.Request) {
+	t, err := h.application.ListTrash(r.Context())
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error retrieving trash from API server", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, t)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(t)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, t)
+	}
+}
+
+// RestorePerson takes a person out of the trash, returning what was restored along with them.
+func (h *HTTPServer) RestorePerson(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	report, err := h.application.RestorePerson(r.Context(), id)
+
+	if errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if renderRelationshipError(w, r, err) {
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error restoring person from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	render.Status(r, http.StatusOK)
+
+	switch r.Header.Get("Accept") {
+	case "application/xml":
+		render.XML(w, r, report)
+	case "application/octet-stream":
+		bytes, _ := json.Marshal(report)
+		render.Data(w, r, bytes)
+	default:
+		render.JSON(w, r, report)
+	}
+}
+
+// PurgePerson deletes a person in the trash for good.
+func (h *HTTPServer) PurgePerson(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	err := h.application.PurgePerson(r.Context(), id)
+
+	if errors.Is(err, app.ErrPersonNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrPersonNotFound))
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error purging person from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RestoreRelationship takes a relationship out of the trash.
+func (h *HTTPServer) RestoreRelationship(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	err := h.application.RestoreRelationship(r.Context(), id)
+
+	if errors.Is(err, app.ErrRelationshipNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrRelationshipNotFound))
+
+		return
+	}
+
+	if renderRelationshipError(w, r, err) {
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error restoring relationship from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// PurgeRelationship deletes a relationship in the trash for good.
+func (h *HTTPServer) PurgeRelationship(w http.ResponseWriter, r *http.Request) {
+	id := chi.URLParam(r, "id")
+
+	err := h.application.PurgeRelationship(r.Context(), id)
+
+	if errors.Is(err, app.ErrRelationshipNotFound) {
+		render.Status(r, http.StatusNotFound)
+		render.PlainText(w, r, fmt.Sprintf("%s", app.ErrRelationshipNotFound))
+
+		return
+	}
+
+	if err != nil {
+		newrelic.FromContext(r.Context()).NoticeError(err)
+		h.log.Error("unexpected error purging relationship from API", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,9 @@
+ALTER TABLE "people"
+	DROP COLUMN IF EXISTS "given_name",
+	DROP COLUMN IF EXISTS "surname",
+	DROP COLUMN IF EXISTS "maiden_name",
+	DROP COLUMN IF EXISTS "sex",
+	DROP COLUMN IF EXISTS "birth_date",
+	DROP COLUMN IF EXISTS "birth_place",
+	DROP COLUMN IF EXISTS "death_date",
+	DROP COLUMN IF EXISTS "death_place";
@@ -0,0 +1,10 @@
+ALTER TABLE "people"
+	ADD COLUMN IF NOT EXISTS "given_name" varchar(255) NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS "surname" varchar(255) NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS "maiden_name" varchar(255) NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS "sex" varchar(16) NOT NULL DEFAULT 'unknown'
+		CHECK ("sex" IN ('male', 'female', 'other', 'unknown')),
+	ADD COLUMN IF NOT EXISTS "birth_date" varchar(64),
+	ADD COLUMN IF NOT EXISTS "birth_place" varchar(255) NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS "death_date" varchar(64),
+	ADD COLUMN IF NOT EXISTS "death_place" varchar(255) NOT NULL DEFAULT '';
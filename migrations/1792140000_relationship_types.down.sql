@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "relationships_type_idx";
+
+ALTER TABLE "relationships"
+	DROP COLUMN IF EXISTS "type";
@@ -0,0 +1,5 @@
+ALTER TABLE "relationships"
+	ADD COLUMN IF NOT EXISTS "type" varchar(16) NOT NULL DEFAULT 'biological'
+		CHECK ("type" IN ('biological', 'adoptive', 'step', 'foster'));
+
+CREATE INDEX IF NOT EXISTS "relationships_type_idx" ON "relationships" ("type");
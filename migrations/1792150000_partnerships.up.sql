@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS "partnerships" (
+	"id" uuid NOT NULL DEFAULT uuid_generate_v4(),
+	"person1_id" uuid NOT NULL,
+	"person2_id" uuid NOT NULL,
+	"type" varchar(16) NOT NULL DEFAULT 'marriage'
+		CHECK ("type" IN ('marriage', 'civil-union', 'partnership')),
+	"start_date" varchar(64),
+	"end_date" varchar(64),
+	"end_reason" varchar(16) NOT NULL DEFAULT ''
+		CHECK ("end_reason" IN ('', 'divorce', 'separation', 'annulment', 'death')),
+	PRIMARY KEY ("id"),
+	FOREIGN KEY ("person1_id") REFERENCES "people" ("id"),
+	FOREIGN KEY ("person2_id") REFERENCES "people" ("id"),
+	CHECK ("person1_id" <> "person2_id")
+);
+
+CREATE INDEX IF NOT EXISTS "partnerships_person1_id_idx" ON "partnerships" ("person1_id");
+CREATE INDEX IF NOT EXISTS "partnerships_person2_id_idx" ON "partnerships" ("person2_id");
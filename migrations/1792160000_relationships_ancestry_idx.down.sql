@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "relationships_parent_id_idx";
+DROP INDEX IF EXISTS "relationships_child_id_type_idx";
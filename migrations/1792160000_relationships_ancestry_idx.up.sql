@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS "relationships_child_id_type_idx" ON "relationships" ("child_id", "type");
+CREATE INDEX IF NOT EXISTS "relationships_parent_id_idx" ON "relationships" ("parent_id");
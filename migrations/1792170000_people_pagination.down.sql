@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS "people_surname_id_idx";
+DROP INDEX IF EXISTS "people_name_id_idx";
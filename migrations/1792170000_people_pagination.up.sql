@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS "people_name_id_idx" ON "people" ("name", "id");
+CREATE INDEX IF NOT EXISTS "people_surname_id_idx" ON "people" ("surname", "id");
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS "people_phonetic_idx";
+DROP INDEX IF EXISTS "people_search_vector_idx";
+DROP INDEX IF EXISTS "people_name_trgm_idx";
+
+ALTER TABLE "people"
+	DROP COLUMN IF EXISTS "search_vector",
+	DROP COLUMN IF EXISTS "phonetic";
+
+DROP EXTENSION IF EXISTS "pg_trgm";
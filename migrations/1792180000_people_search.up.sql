@@ -0,0 +1,14 @@
+CREATE EXTENSION IF NOT EXISTS "pg_trgm";
+
+ALTER TABLE "people"
+	ADD COLUMN IF NOT EXISTS "phonetic" text NOT NULL DEFAULT '',
+	ADD COLUMN IF NOT EXISTS "search_vector" tsvector GENERATED ALWAYS AS (
+		to_tsvector('simple', "name" || ' ' || "given_name" || ' ' || "surname" || ' ' || "maiden_name")
+	) STORED;
+
+-- The phonetic codes are worked out by the application as people are saved,
+-- and for the people already recorded when it starts.
+
+CREATE INDEX IF NOT EXISTS "people_name_trgm_idx" ON "people" USING gin ("name" gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS "people_search_vector_idx" ON "people" USING gin ("search_vector");
+CREATE INDEX IF NOT EXISTS "people_phonetic_idx" ON "people" USING gin (string_to_array("phonetic", ' '));
@@ -0,0 +1,17 @@
+-- Merges keep no foreign keys: the duplicate is deleted by the merge,
+-- and the record of it outlives the survivor as well.
+CREATE TABLE IF NOT EXISTS "person_merges" (
+	"id" uuid NOT NULL DEFAULT uuid_generate_v4(),
+	"survivor_id" uuid NOT NULL,
+	"duplicate_id" uuid NOT NULL,
+	"duplicate_name" varchar(255) NOT NULL DEFAULT '',
+	"relationships_moved" integer NOT NULL DEFAULT 0,
+	"relationships_dropped" integer NOT NULL DEFAULT 0,
+	"partnerships_moved" integer NOT NULL DEFAULT 0,
+	"partnerships_dropped" integer NOT NULL DEFAULT 0,
+	"merged_at" timestamptz NOT NULL DEFAULT now(),
+	PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "person_merges_survivor_id_idx" ON "person_merges" ("survivor_id");
+CREATE INDEX IF NOT EXISTS "person_merges_duplicate_id_idx" ON "person_merges" ("duplicate_id");
@@ -0,0 +1,16 @@
+-- Without the column the trash would come back to life, so it is emptied first.
+DELETE FROM "relationships" WHERE "deleted_at" IS NOT NULL;
+DELETE FROM "partnerships" WHERE "deleted_at" IS NOT NULL;
+DELETE FROM "relationships" r USING "people" p
+	WHERE p."deleted_at" IS NOT NULL AND p."id" IN (r."parent_id", r."child_id");
+DELETE FROM "partnerships" s USING "people" p
+	WHERE p."deleted_at" IS NOT NULL AND p."id" IN (s."person1_id", s."person2_id");
+DELETE FROM "people" WHERE "deleted_at" IS NOT NULL;
+
+DROP INDEX IF EXISTS "partnerships_deleted_at_idx";
+DROP INDEX IF EXISTS "relationships_deleted_at_idx";
+DROP INDEX IF EXISTS "people_deleted_at_idx";
+
+ALTER TABLE "partnerships" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "relationships" DROP COLUMN IF EXISTS "deleted_at";
+ALTER TABLE "people" DROP COLUMN IF EXISTS "deleted_at";
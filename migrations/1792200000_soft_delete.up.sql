@@ -0,0 +1,11 @@
+ALTER TABLE "people" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "relationships" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+ALTER TABLE "partnerships" ADD COLUMN IF NOT EXISTS "deleted_at" timestamptz;
+
+-- The trash is listed and restored by deletion time, and holds few rows.
+CREATE INDEX IF NOT EXISTS "people_deleted_at_idx" ON "people" ("deleted_at")
+	WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS "relationships_deleted_at_idx" ON "relationships" ("deleted_at")
+	WHERE "deleted_at" IS NOT NULL;
+CREATE INDEX IF NOT EXISTS "partnerships_deleted_at_idx" ON "partnerships" ("deleted_at")
+	WHERE "deleted_at" IS NOT NULL;
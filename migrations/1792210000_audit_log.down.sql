@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS "partnerships_audit" ON "partnerships";
+DROP TRIGGER IF EXISTS "relationships_audit" ON "relationships";
+DROP TRIGGER IF EXISTS "people_audit" ON "people";
+
+DROP TABLE IF EXISTS "audit_log";
+
+DROP FUNCTION IF EXISTS audit_log_append_only();
+DROP FUNCTION IF EXISTS audit_change();
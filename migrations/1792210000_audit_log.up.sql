@@ -0,0 +1,87 @@
+-- Every change to people, relationships and partnerships is recorded by
+-- triggers in the transaction making it. The repository hands the actor
+-- and request over with set_config, and the log is never changed after.
+CREATE TABLE IF NOT EXISTS "audit_log" (
+	"id" bigserial NOT NULL,
+	"at" timestamptz NOT NULL DEFAULT now(),
+	"actor" varchar(255),
+	"request_id" varchar(255),
+	"entity" varchar(16) NOT NULL CHECK ("entity" IN ('person', 'relationship', 'partnership')),
+	"entity_id" uuid NOT NULL,
+	"action" varchar(16) NOT NULL CHECK ("action" IN ('create', 'update', 'delete', 'restore', 'purge')),
+	"people" uuid[] NOT NULL,
+	"before" jsonb,
+	"after" jsonb,
+	PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "audit_log_people_idx" ON "audit_log" USING GIN ("people");
+CREATE INDEX IF NOT EXISTS "audit_log_entity_idx" ON "audit_log" ("entity", "entity_id", "id");
+
+CREATE OR REPLACE FUNCTION audit_change() RETURNS trigger AS $$
+DECLARE
+	old_row jsonb;
+	new_row jsonb;
+	change text;
+BEGIN
+	IF TG_OP <> 'INSERT' THEN
+		old_row := to_jsonb(OLD) - 'search_vector' - 'phonetic';
+	END IF;
+
+	IF TG_OP <> 'DELETE' THEN
+		new_row := to_jsonb(NEW) - 'search_vector' - 'phonetic';
+	END IF;
+
+	change := CASE
+		WHEN TG_OP = 'INSERT' THEN 'create'
+		WHEN TG_OP = 'DELETE' AND old_row->>'deleted_at' IS NULL THEN 'delete'
+		WHEN TG_OP = 'DELETE' THEN 'purge'
+		WHEN old_row->>'deleted_at' IS NULL AND new_row->>'deleted_at' IS NOT NULL THEN 'delete'
+		WHEN old_row->>'deleted_at' IS NOT NULL AND new_row->>'deleted_at' IS NULL THEN 'restore'
+		ELSE 'update'
+	END;
+
+	IF old_row = new_row THEN
+		RETURN NULL;
+	END IF;
+
+	INSERT INTO "audit_log" ("actor", "request_id", "entity", "entity_id", "action", "people", "before", "after")
+	SELECT NULLIF(current_setting('familytree.actor', true), ''),
+		NULLIF(current_setting('familytree.request_id', true), ''),
+		CASE TG_TABLE_NAME WHEN 'people' THEN 'person' WHEN 'relationships' THEN 'relationship' ELSE 'partnership' END,
+		CAST(COALESCE(new_row, old_row)->>'id' AS uuid),
+		change,
+		ARRAY(
+			SELECT DISTINCT CAST(v.r->>c.col AS uuid)
+			FROM (VALUES (old_row), (new_row)) AS v(r),
+				unnest(CASE TG_TABLE_NAME
+					WHEN 'people' THEN ARRAY['id']
+					WHEN 'relationships' THEN ARRAY['parent_id', 'child_id']
+					ELSE ARRAY['person1_id', 'person2_id']
+				END) AS c(col)
+			WHERE v.r->>c.col IS NOT NULL
+		),
+		old_row,
+		new_row;
+
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION audit_log_append_only() RETURNS trigger AS $$
+BEGIN
+	RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "people_audit" AFTER INSERT OR UPDATE OR DELETE ON "people"
+	FOR EACH ROW EXECUTE FUNCTION audit_change();
+CREATE TRIGGER "relationships_audit" AFTER INSERT OR UPDATE OR DELETE ON "relationships"
+	FOR EACH ROW EXECUTE FUNCTION audit_change();
+CREATE TRIGGER "partnerships_audit" AFTER INSERT OR UPDATE OR DELETE ON "partnerships"
+	FOR EACH ROW EXECUTE FUNCTION audit_change();
+
+CREATE TRIGGER "audit_log_append_only" BEFORE UPDATE OR DELETE ON "audit_log"
+	FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+CREATE TRIGGER "audit_log_no_truncate" BEFORE TRUNCATE ON "audit_log"
+	FOR EACH STATEMENT EXECUTE FUNCTION audit_log_append_only();
@@ -0,0 +1,97 @@
+package gedcom
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// maxValueLength is how many characters of a value fit in a single line.
+// Longer values are split into CONC lines.
+const maxValueLength = 200
+
+// Encode writes records as GEDCOM lines. Levels are taken from how records
+// are nested rather than from their Level field, and values holding line
+// breaks or too long for a single line are split into CONT and CONC lines.
+func Encode(w io.Writer, rs []*Record) error {
+	bw := bufio.NewWriter(w)
+
+	for _, r := range rs {
+		if err := encode(bw, r, 0); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// encode writes a record and its children at the given level.
+func encode(w *bufio.Writer, r *Record, level int) error {
+	for i, l := range strings.Split(r.Value, "\n") {
+		chunks := split(l)
+
+		var err error
+		if i == 0 {
+			err = writeLine(w, level, r.XRef, r.Tag, chunks[0])
+		} else {
+			err = writeLine(w, level+1, "", "CONT", chunks[0])
+		}
+
+		if err != nil {
+			return err
+		}
+
+		for _, c := range chunks[1:] {
+			if err := writeLine(w, level+1, "", "CONC", c); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, c := range r.Children {
+		if err := encode(w, c, level+1); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// split cuts a value into chunks short enough to fit in a line.
+func split(value string) []string {
+	runes := []rune(value)
+	chunks := []string{string(runes[:minInt(len(runes), maxValueLength)])}
+
+	for i := maxValueLength; i < len(runes); i += maxValueLength {
+		chunks = append(chunks, string(runes[i:minInt(len(runes), i+maxValueLength)]))
+	}
+
+	return chunks
+}
+
+// writeLine writes a single GEDCOM line.
+func writeLine(w *bufio.Writer, level int, xref, tag, value string) error {
+	line := fmt.Sprintf("%d", level)
+	if xref != "" {
+		line += " " + xref
+	}
+
+	line += " " + tag
+	if value != "" {
+		line += " " + value
+	}
+
+	_, err := w.WriteString(line + "\r\n")
+
+	return err
+}
+
+// minInt returns the smallest of two integers.
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,10 @@
+package gedcom
+
+import "errors"
+
+var (
+	// ErrInvalidLine is returned if a line does not follow the GEDCOM line syntax.
+	ErrInvalidLine = errors.New("gedcom: invalid line")
+	// ErrInvalidLevel is returned if a line is nested more than one level below the previous one.
+	ErrInvalidLevel = errors.New("gedcom: invalid level")
+)
@@ -0,0 +1,156 @@
+// Package gedcom reads and writes GEDCOM 5.5.1 files as a tree of records,
+// leaving the meaning of each tag to the caller.
+package gedcom
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Record is a GEDCOM line along with the lines nested below it.
+type Record struct {
+	Level    int
+	XRef     string
+	Tag      string
+	Value    string
+	Line     int
+	Children []*Record
+}
+
+// First returns the first child record with the given tag, or nil.
+func (r *Record) First(tag string) *Record {
+	for _, c := range r.Children {
+		if c.Tag == tag {
+			return c
+		}
+	}
+
+	return nil
+}
+
+// All returns every child record with the given tag.
+func (r *Record) All(tag string) []*Record {
+	rs := make([]*Record, 0)
+
+	for _, c := range r.Children {
+		if c.Tag == tag {
+			rs = append(rs, c)
+		}
+	}
+
+	return rs
+}
+
+// ValueOf returns the value of the first child record with the given tag,
+// or an empty string if there is none.
+func (r *Record) ValueOf(tag string) string {
+	if c := r.First(tag); c != nil {
+		return c.Value
+	}
+
+	return ""
+}
+
+// Parse reads a GEDCOM stream and returns its level zero records.
+// CONC and CONT lines are folded into the value of the line they continue.
+func Parse(reader io.Reader) ([]*Record, error) {
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 1<<20)
+
+	var (
+		roots []*Record
+		stack []*Record
+	)
+
+	for n := 1; scanner.Scan(); n++ {
+		text := strings.TrimRight(scanner.Text(), "\r")
+		if n == 1 {
+			text = strings.TrimPrefix(text, "\ufeff")
+		}
+
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+
+		rec, err := parseLine(text, n)
+		if err != nil {
+			return nil, err
+		}
+
+		if rec.Level > len(stack) {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidLevel, n)
+		}
+
+		stack = stack[:rec.Level]
+
+		if rec.Level > 0 && (rec.Tag == "CONC" || rec.Tag == "CONT") {
+			parent := stack[rec.Level-1]
+			if rec.Tag == "CONT" {
+				parent.Value += "\n"
+			}
+
+			parent.Value += rec.Value
+
+			continue
+		}
+
+		if rec.Level == 0 {
+			roots = append(roots, rec)
+		} else {
+			parent := stack[rec.Level-1]
+			parent.Children = append(parent.Children, rec)
+		}
+
+		stack = append(stack, rec)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return roots, nil
+}
+
+// parseLine splits a line into level, optional cross-reference, tag and value.
+func parseLine(text string, n int) (*Record, error) {
+	text = strings.TrimLeft(text, " \t")
+
+	fields := strings.SplitN(text, " ", 2)
+	if len(fields) < 2 {
+		return nil, fmt.Errorf("%w: line %d", ErrInvalidLine, n)
+	}
+
+	level, err := strconv.Atoi(fields[0])
+	if err != nil || level < 0 {
+		return nil, fmt.Errorf("%w: line %d", ErrInvalidLine, n)
+	}
+
+	rec := &Record{Level: level, Line: n}
+	rest := fields[1]
+
+	if strings.HasPrefix(rest, "@") {
+		fields = strings.SplitN(rest, " ", 2)
+		if len(fields) < 2 || len(fields[0]) < 3 || !strings.HasSuffix(fields[0], "@") {
+			return nil, fmt.Errorf("%w: line %d", ErrInvalidLine, n)
+		}
+
+		rec.XRef = fields[0]
+		rest = fields[1]
+	}
+
+	fields = strings.SplitN(rest, " ", 2)
+	rec.Tag = strings.ToUpper(fields[0])
+
+	if rec.Tag == "" {
+		return nil, fmt.Errorf("%w: line %d", ErrInvalidLine, n)
+	}
+
+	if len(fields) == 2 {
+		rec.Value = fields[1]
+	}
+
+	return rec, nil
+}
@@ -0,0 +1,80 @@
+package gedcom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const sample = "\ufeff0 HEAD\r\n" +
+	"1 CHAR UTF-8\r\n" +
+	"0 @I1@ INDI\r\n" +
+	"1 NAME John /Smith/\r\n" +
+	"1 NOTE First line\r\n" +
+	"2 CONT second line\r\n" +
+	"2 CONC , continued\r\n" +
+	"0 @F1@ FAM\r\n" +
+	"1 HUSB @I1@\r\n" +
+	"1 CHIL @I2@\r\n" +
+	"1 CHIL @I3@\r\n" +
+	"0 TRLR\r\n"
+
+func Test_Parse(t *testing.T) {
+	rs, err := Parse(strings.NewReader(sample))
+
+	assert.NoError(t, err)
+	assert.Len(t, rs, 4)
+	assert.Equal(t, "HEAD", rs[0].Tag)
+	assert.Equal(t, "UTF-8", rs[0].ValueOf("CHAR"))
+
+	assert.Equal(t, "@I1@", rs[1].XRef)
+	assert.Equal(t, "INDI", rs[1].Tag)
+	assert.Equal(t, "John /Smith/", rs[1].ValueOf("NAME"))
+	assert.Equal(t, "First line\nsecond line, continued", rs[1].ValueOf("NOTE"))
+	assert.Len(t, rs[1].First("NOTE").Children, 0)
+
+	assert.Equal(t, 8, rs[2].Line)
+	assert.Len(t, rs[2].All("CHIL"), 2)
+	assert.Nil(t, rs[2].First("WIFE"))
+}
+
+func Test_Parse_Invalid(t *testing.T) {
+	_, err := Parse(strings.NewReader("0 HEAD\n2 CHAR UTF-8\n"))
+	assert.ErrorIs(t, err, ErrInvalidLevel)
+
+	_, err = Parse(strings.NewReader("0 HEAD\nHEAD\n"))
+	assert.ErrorIs(t, err, ErrInvalidLine)
+
+	_, err = Parse(strings.NewReader("0 @I1 INDI\n"))
+	assert.ErrorIs(t, err, ErrInvalidLine)
+}
+
+func Test_Encode(t *testing.T) {
+	rs := []*Record{
+		{Tag: "HEAD", Children: []*Record{{Tag: "CHAR", Value: "UTF-8"}}},
+		{XRef: "@I1@", Tag: "INDI", Children: []*Record{
+			{Tag: "NAME", Value: "John /Smith/"},
+			{Tag: "NOTE", Value: "First line\n" + strings.Repeat("a", 250)},
+		}},
+		{Tag: "TRLR"},
+	}
+
+	var b strings.Builder
+
+	assert.NoError(t, Encode(&b, rs))
+
+	expected := "0 HEAD\r\n" +
+		"1 CHAR UTF-8\r\n" +
+		"0 @I1@ INDI\r\n" +
+		"1 NAME John /Smith/\r\n" +
+		"1 NOTE First line\r\n" +
+		"2 CONT " + strings.Repeat("a", 200) + "\r\n" +
+		"2 CONC " + strings.Repeat("a", 50) + "\r\n" +
+		"0 TRLR\r\n"
+	assert.Equal(t, expected, b.String())
+
+	parsed, err := Parse(strings.NewReader(b.String()))
+	assert.NoError(t, err)
+	assert.Equal(t, rs[1].Children[1].Value, parsed[1].ValueOf("NOTE"))
+}
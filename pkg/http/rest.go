@@ -27,7 +27,7 @@ const formatKey key = "format"
 // RestConfig holds all necessary configuration to run module rest.
 type RestConfig struct {
 	CorsAllowedOrigins  []string `split_words:"true" required:"false" default:"*"`
-	CorsAllowedHeaders  []string `split_words:"true" required:"false" default:"Accept,Authorization,Content-Type"`
+	CorsAllowedHeaders  []string `split_words:"true" required:"false" default:"Accept,Authorization,Content-Type,X-Actor"`
 	CorsAllowedMehthods []string `split_words:"true" required:"false" default:"GET,POST,HEAD"`
 }
 
@@ -112,8 +112,14 @@ func FormatMiddleware(next http.Handler) http.Handler {
 
 func SetContentTypeMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		format := r.Context().Value("format")
+		format := r.Context().Value(formatKey)
 		switch format {
+		case "text/x-gedcom":
+			w.Header().Set("Content-Type", "text/x-gedcom")
+		case "text/vnd.graphviz":
+			w.Header().Set("Content-Type", "text/vnd.graphviz")
+		case "image/svg+xml":
+			w.Header().Set("Content-Type", "image/svg+xml")
 		case "application/xml":
 			w.Header().Set("Content-Type", "application/xml")
 		case "application/octet-stream":
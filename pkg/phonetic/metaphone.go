@@ -0,0 +1,669 @@
+package phonetic
+
+import "strings"
+
+// metaphoneLength is the length Double Metaphone codes are cut to.
+const metaphoneLength = 4
+
+// DoubleMetaphone returns the primary and alternate Double Metaphone codes
+// of a word, after Lawrence Philips' algorithm. The alternate code tells
+// another common way to pronounce the word, such as XMT for Schmidt besides
+// SMT, and is the primary one when there is no other.
+func DoubleMetaphone(word string) (string, string) {
+	w := &metaphoneWord{value: []rune(strings.Join(Words(word), " "))}
+	if len(w.value) == 0 {
+		return "", ""
+	}
+
+	v := string(w.value)
+	w.slavoGermanic = strings.ContainsAny(v, "WK") || strings.Contains(v, "CZ")
+
+	i := 0
+	if w.has(0, 2, "GN", "KN", "PN", "WR", "PS") {
+		i = 1
+	}
+
+	for !w.complete() && i < len(w.value) {
+		switch w.at(i) {
+		case 'A', 'E', 'I', 'O', 'U', 'Y':
+			if i == 0 {
+				w.add("A")
+			}
+
+			i++
+		case 'B':
+			w.add("P")
+			i = w.skip(i, 'B')
+		case 'Ç':
+			w.add("S")
+			i++
+		case 'C':
+			i = w.c(i)
+		case 'D':
+			i = w.d(i)
+		case 'F':
+			w.add("F")
+			i = w.skip(i, 'F')
+		case 'G':
+			i = w.g(i)
+		case 'H':
+			i = w.h(i)
+		case 'J':
+			i = w.j(i)
+		case 'K':
+			w.add("K")
+			i = w.skip(i, 'K')
+		case 'L':
+			i = w.l(i)
+		case 'M':
+			w.add("M")
+			i = w.m(i)
+		case 'N':
+			w.add("N")
+			i = w.skip(i, 'N')
+		case 'Ñ':
+			w.add("N")
+			i++
+		case 'P':
+			i = w.p(i)
+		case 'Q':
+			w.add("K")
+			i = w.skip(i, 'Q')
+		case 'R':
+			i = w.r(i)
+		case 'S':
+			i = w.s(i)
+		case 'T':
+			i = w.t(i)
+		case 'V':
+			w.add("F")
+			i = w.skip(i, 'V')
+		case 'W':
+			i = w.w(i)
+		case 'X':
+			i = w.x(i)
+		case 'Z':
+			i = w.z(i)
+		default:
+			i++
+		}
+	}
+
+	return w.primary.String(), w.alternate.String()
+}
+
+// metaphoneWord holds a word being encoded and its codes so far.
+type metaphoneWord struct {
+	value              []rune
+	slavoGermanic      bool
+	primary, alternate strings.Builder
+}
+
+// at returns the letter at i, or zero when i is out of the word.
+func (w *metaphoneWord) at(i int) rune {
+	if i < 0 || i >= len(w.value) {
+		return 0
+	}
+
+	return w.value[i]
+}
+
+// has reports whether the n letters starting at i are any of the given strings.
+func (w *metaphoneWord) has(i, n int, ss ...string) bool {
+	if i < 0 || i+n > len(w.value) {
+		return false
+	}
+
+	sub := string(w.value[i : i+n])
+
+	for _, s := range ss {
+		if sub == s {
+			return true
+		}
+	}
+
+	return false
+}
+
+// vowel reports whether the letter at i is a vowel.
+func (w *metaphoneWord) vowel(i int) bool {
+	return strings.ContainsRune("AEIOUY", w.at(i))
+}
+
+// skip returns the index after a letter, skipping it when it is doubled.
+func (w *metaphoneWord) skip(i int, r rune) int {
+	if w.at(i+1) == r {
+		return i + 2
+	}
+
+	return i + 1
+}
+
+// add appends the same sound to both codes.
+func (w *metaphoneWord) add(s string) {
+	w.addBoth(s, s)
+}
+
+// addBoth appends a sound to the primary code and another to the alternate one.
+func (w *metaphoneWord) addBoth(primary, alternate string) {
+	w.addPrimary(primary)
+	w.addAlternate(alternate)
+}
+
+// addPrimary appends a sound to the primary code only.
+func (w *metaphoneWord) addPrimary(s string) {
+	w.primary.WriteString(s[:minLen(len(s), metaphoneLength-w.primary.Len())])
+}
+
+// addAlternate appends a sound to the alternate code only.
+func (w *metaphoneWord) addAlternate(s string) {
+	w.alternate.WriteString(s[:minLen(len(s), metaphoneLength-w.alternate.Len())])
+}
+
+// complete reports whether both codes reached their full length.
+func (w *metaphoneWord) complete() bool {
+	return w.primary.Len() >= metaphoneLength && w.alternate.Len() >= metaphoneLength
+}
+
+// c encodes the letter C at i and returns the index of the next letter.
+func (w *metaphoneWord) c(i int) int {
+	switch {
+	case w.chAsK(i):
+		// as in "bacher" and "macher"
+		w.add("K")
+
+		return i + 2
+	case i == 0 && w.has(i, 6, "CAESAR"):
+		w.add("S")
+
+		return i + 2
+	case w.has(i, 2, "CH"):
+		return w.ch(i)
+	case w.has(i, 2, "CZ") && !w.has(i-2, 4, "WICZ"):
+		// as in "czerny"
+		w.addBoth("S", "X")
+
+		return i + 2
+	case w.has(i+1, 3, "CIA"):
+		// as in "focaccia"
+		w.add("X")
+
+		return i + 3
+	case w.has(i, 2, "CC") && !(i == 1 && w.at(0) == 'M'):
+		// double C, but not as in "McClellan"
+		if w.has(i+2, 1, "I", "E", "H") && !w.has(i+2, 2, "HU") {
+			if (i == 1 && w.at(i-1) == 'A') || w.has(i-1, 5, "UCCEE", "UCCES") {
+				// as in "accident" and "success"
+				w.add("KS")
+			} else {
+				// as in "bacci" and "bertucci"
+				w.add("X")
+			}
+
+			return i + 3
+		}
+
+		w.add("K")
+
+		return i + 2
+	case w.has(i, 2, "CK", "CG", "CQ"):
+		w.add("K")
+
+		return i + 2
+	case w.has(i, 2, "CI", "CE", "CY"):
+		// Italian as against English
+		if w.has(i, 3, "CIO", "CIE", "CIA") {
+			w.addBoth("S", "X")
+		} else {
+			w.add("S")
+		}
+
+		return i + 2
+	}
+
+	w.add("K")
+
+	switch {
+	case w.has(i+1, 2, " C", " Q", " G"):
+		// as in "Mac Caffrey" and "Mac Gregor"
+		return i + 3
+	case w.has(i+1, 1, "C", "K", "Q") && !w.has(i+1, 2, "CE", "CI"):
+		return i + 2
+	default:
+		return i + 1
+	}
+}
+
+// chAsK reports whether the C at i is a hard one before an H,
+// as in the Germanic "bacher" and the Italian "chianti".
+func (w *metaphoneWord) chAsK(i int) bool {
+	switch {
+	case w.has(i, 4, "CHIA"):
+		return true
+	case i <= 1, w.vowel(i - 2), !w.has(i-1, 3, "ACH"):
+		return false
+	}
+
+	next := w.at(i + 2)
+
+	return (next != 'I' && next != 'E') || w.has(i-2, 6, "BACHER", "MACHER")
+}
+
+// ch encodes the letters CH at i and returns the index of the next letter.
+func (w *metaphoneWord) ch(i int) int {
+	switch {
+	case i > 0 && w.has(i, 4, "CHAE"):
+		// as in "Michael"
+		w.addBoth("K", "X")
+	case i == 0 && (w.has(i+1, 5, "HARAC", "HARIS") || w.has(i+1, 3, "HOR", "HYM", "HIA", "HEM")) &&
+		!w.has(0, 5, "CHORE"):
+		// Greek roots, as in "chemistry" and "chorus"
+		w.add("K")
+	case w.has(0, 4, "VAN ", "VON ") || w.has(0, 3, "SCH") ||
+		w.has(i-2, 6, "ORCHES", "ARCHIT", "ORCHID") || w.has(i+2, 1, "T", "S") ||
+		((w.has(i-1, 1, "A", "O", "U", "E") || i == 0) &&
+			(w.has(i+2, 1, "L", "R", "N", "M", "B", "H", "F", "V", "W", " ") || i+1 == len(w.value)-1)):
+		// Germanic, Greek, or otherwise hard, as in "Bach" and "orchestra"
+		w.add("K")
+	case i > 0:
+		if w.has(0, 2, "MC") {
+			// as in "McHugh"
+			w.add("K")
+		} else {
+			w.addBoth("X", "K")
+		}
+	default:
+		w.add("X")
+	}
+
+	return i + 2
+}
+
+// d encodes the letter D at i and returns the index of the next letter.
+func (w *metaphoneWord) d(i int) int {
+	switch {
+	case w.has(i, 2, "DG"):
+		if w.has(i+2, 1, "I", "E", "Y") {
+			// as in "edge"
+			w.add("J")
+
+			return i + 3
+		}
+
+		// as in "Edgar"
+		w.add("TK")
+
+		return i + 2
+	case w.has(i, 2, "DT", "DD"):
+		w.add("T")
+
+		return i + 2
+	}
+
+	w.add("T")
+
+	return i + 1
+}
+
+// g encodes the letter G at i and returns the index of the next letter.
+func (w *metaphoneWord) g(i int) int {
+	switch {
+	case w.at(i+1) == 'H':
+		return w.gh(i)
+	case w.at(i+1) == 'N':
+		switch {
+		case i == 1 && w.vowel(0) && !w.slavoGermanic:
+			w.addBoth("KN", "N")
+		case !w.has(i+2, 2, "EY") && w.at(i+1) != 'Y' && !w.slavoGermanic:
+			// not as in "Cagney"
+			w.addBoth("N", "KN")
+		default:
+			w.add("KN")
+		}
+
+		return i + 2
+	case w.has(i+1, 2, "LI") && !w.slavoGermanic:
+		// as in "tagliaro"
+		w.addBoth("KL", "L")
+
+		return i + 2
+	case i == 0 && (w.at(i+1) == 'Y' ||
+		w.has(i+1, 2, "ES", "EP", "EB", "EL", "EY", "IB", "IL", "IN", "IE", "EI", "ER")):
+		// -ges-, -gep-, -gel- and -gie- at the beginning
+		w.addBoth("K", "J")
+
+		return i + 2
+	case (w.has(i+1, 2, "ER") || w.at(i+1) == 'Y') &&
+		!w.has(0, 6, "DANGER", "RANGER", "MANGER") &&
+		!w.has(i-1, 1, "E", "I") && !w.has(i-1, 3, "RGY", "OGY"):
+		// -ger- and -gy-
+		w.addBoth("K", "J")
+
+		return i + 2
+	case w.has(i+1, 1, "E", "I", "Y") || w.has(i-1, 4, "AGGI", "OGGI"):
+		// Italian, as in "biaggi"
+		switch {
+		case w.has(0, 4, "VAN ", "VON ") || w.has(0, 3, "SCH") || w.has(i+1, 2, "ET"):
+			// obvious Germanic
+			w.add("K")
+		case w.has(i+1, 3, "IER"):
+			w.add("J")
+		default:
+			w.addBoth("J", "K")
+		}
+
+		return i + 2
+	}
+
+	w.add("K")
+
+	return w.skip(i, 'G')
+}
+
+// gh encodes the letters GH at i and returns the index of the next letter.
+func (w *metaphoneWord) gh(i int) int {
+	switch {
+	case i > 0 && !w.vowel(i-1):
+		w.add("K")
+	case i == 0:
+		// as in "ghislane" and "ghiradelli"
+		if w.at(i+2) == 'I' {
+			w.add("J")
+		} else {
+			w.add("K")
+		}
+	case (i > 1 && w.has(i-2, 1, "B", "H", "D")) ||
+		(i > 2 && w.has(i-3, 1, "B", "H", "D")) ||
+		(i > 3 && w.has(i-4, 1, "B", "H")):
+		// silent, as in "Hugh", "bough" and "broughton"
+	case i > 2 && w.at(i-1) == 'U' && w.has(i-3, 1, "C", "G", "L", "R", "T"):
+		// as in "laugh", "McLaughlin", "cough", "rough" and "tough"
+		w.add("F")
+	case w.at(i-1) != 'I':
+		w.add("K")
+	}
+
+	return i + 2
+}
+
+// h encodes the letter H at i and returns the index of the next letter.
+// It is only kept between vowels or at the beginning before one.
+func (w *metaphoneWord) h(i int) int {
+	if (i == 0 || w.vowel(i-1)) && w.vowel(i+1) {
+		w.add("H")
+
+		return i + 2
+	}
+
+	return i + 1
+}
+
+// j encodes the letter J at i and returns the index of the next letter.
+func (w *metaphoneWord) j(i int) int {
+	if w.has(i, 4, "JOSE") || w.has(0, 4, "SAN ") {
+		// Spanish, as in "Jose" and "San Jacinto"
+		if (i == 0 && w.at(i+4) == ' ') || len(w.value) == 4 || w.has(0, 4, "SAN ") {
+			w.add("H")
+		} else {
+			w.addBoth("J", "H")
+		}
+
+		return i + 1
+	}
+
+	switch {
+	case i == 0:
+		// as in "Yankelovich" and "Jankelowicz"
+		w.addBoth("J", "A")
+	case w.vowel(i-1) && !w.slavoGermanic && (w.at(i+1) == 'A' || w.at(i+1) == 'O'):
+		// Spanish, as in "bajador"
+		w.addBoth("J", "H")
+	case i == len(w.value)-1:
+		w.addPrimary("J")
+	case !w.has(i+1, 1, "L", "T", "K", "S", "N", "M", "B", "Z") && !w.has(i-1, 1, "S", "K", "L"):
+		w.add("J")
+	}
+
+	return w.skip(i, 'J')
+}
+
+// l encodes the letter L at i and returns the index of the next letter.
+func (w *metaphoneWord) l(i int) int {
+	if w.at(i+1) != 'L' {
+		w.add("L")
+
+		return i + 1
+	}
+
+	n := len(w.value)
+
+	if (i == n-3 && w.has(i-1, 4, "ILLO", "ILLA", "ALLE")) ||
+		((w.has(n-2, 2, "AS", "OS") || w.has(n-1, 1, "A", "O")) && w.has(i-1, 4, "ALLE")) {
+		// Spanish, as in "cabrillo" and "gallegos"
+		w.addPrimary("L")
+	} else {
+		w.add("L")
+	}
+
+	return i + 2
+}
+
+// m returns the index of the letter after the M at i,
+// skipping a B that is silent as in "dumb" and "thumb".
+func (w *metaphoneWord) m(i int) int {
+	if w.at(i+1) == 'M' ||
+		(w.has(i-1, 3, "UMB") && (i+1 == len(w.value)-1 || w.has(i+2, 2, "ER"))) {
+		return i + 2
+	}
+
+	return i + 1
+}
+
+// p encodes the letter P at i and returns the index of the next letter.
+func (w *metaphoneWord) p(i int) int {
+	if w.at(i+1) == 'H' {
+		w.add("F")
+
+		return i + 2
+	}
+
+	w.add("P")
+
+	// as in "Campbell" and "raspberry"
+	if w.has(i+1, 1, "P", "B") {
+		return i + 2
+	}
+
+	return i + 1
+}
+
+// r encodes the letter R at i and returns the index of the next letter.
+func (w *metaphoneWord) r(i int) int {
+	if i == len(w.value)-1 && !w.slavoGermanic && w.has(i-2, 2, "IE") && !w.has(i-4, 2, "ME", "MA") {
+		// French, as in "Rogier", but not "Hochmeier"
+		w.addAlternate("R")
+	} else {
+		w.add("R")
+	}
+
+	return w.skip(i, 'R')
+}
+
+// s encodes the letter S at i and returns the index of the next letter.
+func (w *metaphoneWord) s(i int) int {
+	switch {
+	case w.has(i-1, 3, "ISL", "YSL"):
+		// silent, as in "island" and "carlysle"
+		return i + 1
+	case i == 0 && w.has(i, 5, "SUGAR"):
+		w.addBoth("X", "S")
+
+		return i + 1
+	case w.has(i, 2, "SH"):
+		if w.has(i+1, 4, "HEIM", "HOEK", "HOLM", "HOLZ") {
+			// Germanic
+			w.add("S")
+		} else {
+			w.add("X")
+		}
+
+		return i + 2
+	case w.has(i, 3, "SIO", "SIA") || w.has(i, 4, "SIAN"):
+		// Italian and Armenian
+		if w.slavoGermanic {
+			w.add("S")
+		} else {
+			w.addBoth("S", "X")
+		}
+
+		return i + 3
+	case (i == 0 && w.has(i+1, 1, "M", "N", "L", "W")) || w.has(i+1, 1, "Z"):
+		// German and anglicisations, as in "Smith" against "Schmidt"
+		w.addBoth("S", "X")
+
+		return w.skip(i, 'Z')
+	case w.has(i, 2, "SC"):
+		return w.sc(i)
+	}
+
+	if i == len(w.value)-1 && w.has(i-2, 2, "AI", "OI") {
+		// French, as in "resnais" and "artois"
+		w.addAlternate("S")
+	} else {
+		w.add("S")
+	}
+
+	if w.has(i+1, 1, "S", "Z") {
+		return i + 2
+	}
+
+	return i + 1
+}
+
+// sc encodes the letters SC at i and returns the index of the next letter.
+func (w *metaphoneWord) sc(i int) int {
+	switch {
+	case w.at(i+2) == 'H':
+		switch {
+		case w.has(i+3, 2, "ER", "EN"):
+			// Dutch, as in "schenker" and "schermerhorn"
+			w.addBoth("X", "SK")
+		case w.has(i+3, 2, "OO", "UY", "ED", "EM"):
+			// as in "schooner"
+			w.add("SK")
+		case i == 0 && !w.vowel(3) && w.at(3) != 'W':
+			w.addBoth("X", "S")
+		default:
+			w.add("X")
+		}
+	case w.has(i+2, 1, "I", "E", "Y"):
+		w.add("S")
+	default:
+		w.add("SK")
+	}
+
+	return i + 3
+}
+
+// t encodes the letter T at i and returns the index of the next letter.
+func (w *metaphoneWord) t(i int) int {
+	switch {
+	case w.has(i, 4, "TION"), w.has(i, 3, "TIA", "TCH"):
+		w.add("X")
+
+		return i + 3
+	case w.has(i, 2, "TH"), w.has(i, 3, "TTH"):
+		if w.has(i+2, 2, "OM", "AM") || w.has(0, 4, "VAN ", "VON ") || w.has(0, 3, "SCH") {
+			// as in "Thomas" and "Thames"
+			w.add("T")
+		} else {
+			w.addBoth("0", "T")
+		}
+
+		return i + 2
+	}
+
+	w.add("T")
+
+	if w.has(i+1, 1, "T", "D") {
+		return i + 2
+	}
+
+	return i + 1
+}
+
+// w encodes the letter W at i and returns the index of the next letter.
+func (w *metaphoneWord) w(i int) int {
+	switch {
+	case w.has(i, 2, "WR"):
+		// as in "Wright"
+		w.add("R")
+
+		return i + 2
+	case i == 0 && (w.vowel(i+1) || w.has(i, 2, "WH")):
+		// "Wasserman" sounds like "Vasserman"
+		if w.vowel(i + 1) {
+			w.addBoth("A", "F")
+		} else {
+			w.add("A")
+		}
+	case (i == len(w.value)-1 && w.vowel(i-1)) ||
+		w.has(i-1, 5, "EWSKI", "EWSKY", "OWSKI", "OWSKY") || w.has(0, 3, "SCH"):
+		// Polish, as in "Filipowicz"
+		w.addAlternate("F")
+	case w.has(i, 4, "WICZ", "WITZ"):
+		w.addBoth("TS", "FX")
+
+		return i + 4
+	}
+
+	return i + 1
+}
+
+// x encodes the letter X at i and returns the index of the next letter.
+func (w *metaphoneWord) x(i int) int {
+	if i == 0 {
+		// as in "Xavier"
+		w.add("S")
+
+		return i + 1
+	}
+
+	if !(i == len(w.value)-1 && (w.has(i-3, 3, "IAU", "EAU") || w.has(i-2, 2, "AU", "OU"))) {
+		// not French, as in "breaux"
+		w.add("KS")
+	}
+
+	if w.has(i+1, 1, "C", "X") {
+		return i + 2
+	}
+
+	return i + 1
+}
+
+// z encodes the letter Z at i and returns the index of the next letter.
+func (w *metaphoneWord) z(i int) int {
+	if w.at(i+1) == 'H' {
+		// Chinese, as in "Zhao"
+		w.add("J")
+
+		return i + 2
+	}
+
+	if w.has(i+1, 2, "ZO", "ZI", "ZA") || (w.slavoGermanic && i > 0 && w.at(i-1) != 'T') {
+		w.addBoth("S", "TS")
+	} else {
+		w.add("S")
+	}
+
+	return w.skip(i, 'Z')
+}
+
+// minLen returns the smallest of two lengths.
+func minLen(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
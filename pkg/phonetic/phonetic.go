@@ -0,0 +1,63 @@
+// Package phonetic encodes names after how they sound, so that names spelt
+// differently but pronounced alike, such as Smith and Smyth, share a code.
+package phonetic
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Codes returns the Soundex and Double Metaphone codes of every word of
+// the given names, without repetitions and in the order first found.
+func Codes(names ...string) []string {
+	seen := make(map[string]bool)
+	codes := make([]string, 0)
+
+	add := func(c string) {
+		if c != "" && !seen[c] {
+			seen[c] = true
+			codes = append(codes, c)
+		}
+	}
+
+	for _, name := range names {
+		for _, w := range Words(name) {
+			primary, alternate := DoubleMetaphone(w)
+
+			add(Soundex(w))
+			add(primary)
+			add(alternate)
+		}
+	}
+
+	return codes
+}
+
+// Words splits a name into its words, upper cased and with the accents
+// removed, other than those of Ç and Ñ which change how a word sounds.
+// Apostrophes are dropped rather than splitting words, as in O'Brien.
+func Words(name string) []string {
+	return strings.FieldsFunc(fold(name), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
+
+// fold upper cases s and strips its diacritics, keeping Ç and Ñ.
+func fold(s string) string {
+	s = strings.NewReplacer("ç", "\x00C", "Ç", "\x00C", "ñ", "\x00N", "Ñ", "\x00N").Replace(s)
+
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+
+	folded = strings.NewReplacer("\x00C", "Ç", "\x00N", "Ñ", "ß", "SS", "'", "", "’", "").Replace(folded)
+
+	return strings.ToUpper(folded)
+}
@@ -0,0 +1,61 @@
+package phonetic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Soundex(t *testing.T) {
+	for word, code := range map[string]string{
+		"Robert":   "R163",
+		"Rupert":   "R163",
+		"Rubin":    "R150",
+		"Ashcraft": "A261",
+		"Ashcroft": "A261",
+		"Tymczak":  "T522",
+		"Pfister":  "P236",
+		"Honeyman": "H555",
+		"Lee":      "L000",
+		"Müller":   "M460",
+		"O'Brien":  "O165",
+		"":         "",
+		"123":      "",
+	} {
+		assert.Equal(t, code, Soundex(word), word)
+	}
+}
+
+func Test_DoubleMetaphone(t *testing.T) {
+	for word, codes := range map[string][2]string{
+		"Smith":     {"SM0", "XMT"},
+		"Schmidt":   {"XMT", "SMT"},
+		"Thompson":  {"TMPS", "TMPS"},
+		"Jose":      {"HS", "HS"},
+		"Xavier":    {"SF", "SFR"},
+		"Catherine": {"K0RN", "KTRN"},
+		"Katherine": {"K0RN", "KTRN"},
+		"Michael":   {"MKL", "MXL"},
+		"Gallegos":  {"KLKS", "KKS"},
+		"Knight":    {"NT", "NT"},
+		"Wright":    {"RT", "RT"},
+		"Philip":    {"FLP", "FLP"},
+		"Dumb":      {"TM", "TM"},
+		"Laugh":     {"LF", "LF"},
+		"Edge":      {"AJ", "AJ"},
+		"Czerny":    {"SRN", "XRN"},
+		"Gonçalves": {"KNSL", "KNSL"},
+		"":          {"", ""},
+	} {
+		primary, alternate := DoubleMetaphone(word)
+
+		assert.Equal(t, codes[0], primary, word)
+		assert.Equal(t, codes[1], alternate, word)
+	}
+}
+
+func Test_Codes(t *testing.T) {
+	assert.Equal(t, []string{"S530", "SM0", "XMT", "J500", "JN", "AN"}, Codes("Smith", "John Smith", "Jon"))
+	assert.Subset(t, Codes("Smyth"), []string{"S530", "SM0", "XMT"})
+	assert.Empty(t, Codes(""))
+}
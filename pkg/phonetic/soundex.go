@@ -0,0 +1,65 @@
+package phonetic
+
+// soundexCodes maps the letters A to Z to their Soundex digit,
+// with zero for the vowels and for H, W and Y.
+const soundexCodes = "01230120022455012623010202"
+
+// Soundex returns the American Soundex code of a word: its first letter
+// followed by three digits, as in R163 for both Robert and Rupert.
+// Letters other than A to Z are ignored, and an empty string is
+// returned for words without any.
+func Soundex(word string) string {
+	code := make([]byte, 0, 4)
+
+	var last byte
+
+	for _, r := range fold(word) {
+		switch r {
+		case 'Ç':
+			r = 'C'
+		case 'Ñ':
+			r = 'N'
+		}
+
+		if r < 'A' || r > 'Z' {
+			continue
+		}
+
+		digit := soundexCodes[r-'A']
+
+		if len(code) == 0 {
+			code = append(code, byte(r))
+			last = digit
+
+			continue
+		}
+
+		switch {
+		case r == 'H' || r == 'W':
+			// H and W do not separate letters with the same digit.
+			continue
+		case digit == '0':
+			last = 0
+
+			continue
+		case digit != last:
+			code = append(code, digit)
+		}
+
+		last = digit
+
+		if len(code) == cap(code) {
+			break
+		}
+	}
+
+	if len(code) == 0 {
+		return ""
+	}
+
+	for len(code) < cap(code) {
+		code = append(code, '0')
+	}
+
+	return string(code)
+}